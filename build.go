@@ -0,0 +1,239 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime/debug"
+	"sort"
+	"strings"
+)
+
+type buildConfig struct {
+	names    []string
+	srcDir   string
+	outDir   string
+	hooksDir string
+}
+
+func parseBuildArgs(args []string) (*buildConfig, error) {
+	cfg := &buildConfig{
+		srcDir: "plugins",
+		outDir: filepath.Join(".", ".claude", "hooks"),
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch arg {
+		case "--src", "--out", "--hooks-dir":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s requires a directory path", arg)
+			}
+			i++
+			switch arg {
+			case "--src":
+				cfg.srcDir = args[i]
+			case "--out":
+				cfg.outDir = args[i]
+			case "--hooks-dir":
+				cfg.hooksDir = args[i]
+			}
+
+		default:
+			if strings.HasPrefix(arg, "-") {
+				return nil, fmt.Errorf("unknown option: %q", arg)
+			}
+			cfg.names = append(cfg.names, arg)
+		}
+	}
+
+	if cfg.hooksDir == "" {
+		dir, err := defaultPluginDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine hooks directory: %w", err)
+		}
+		cfg.hooksDir = dir
+	}
+
+	return cfg, nil
+}
+
+func handleBuildCommand(args []string) error {
+	cfg, err := parseBuildArgs(args)
+	if err != nil {
+		return err
+	}
+
+	_, err = buildPlugins(cfg)
+	return err
+}
+
+func handleInstallCommand(args []string) error {
+	cfg, err := parseBuildArgs(args)
+	if err != nil {
+		return err
+	}
+
+	built, err := buildPlugins(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(cfg.hooksDir, 0755); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	for _, path := range built {
+		dst := filepath.Join(cfg.hooksDir, filepath.Base(path))
+		if err := installFile(path, dst); err != nil {
+			return fmt.Errorf("failed to install %s: %w", filepath.Base(path), err)
+		}
+		fmt.Printf("✓ %s installed to %s\n", filepath.Base(path), cfg.hooksDir)
+	}
+	return nil
+}
+
+// buildPlugins 使用与当前程序相同的工具链和编译参数编译插件，返回生成的.so路径
+func buildPlugins(cfg *buildConfig) ([]string, error) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil, errors.New("build information is not available in this binary")
+	}
+
+	env, flags, err := hostBuildSettings(info)
+	if err != nil {
+		return nil, err
+	}
+
+	names := cfg.names
+	if len(names) == 0 {
+		names, err = discoverPlugins(cfg.srcDir)
+		if err != nil {
+			return nil, err
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("no plugins found in %s", cfg.srcDir)
+		}
+	}
+
+	if err := os.MkdirAll(cfg.outDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	built := make([]string, 0, len(names))
+	for _, name := range names {
+		pkgDir := filepath.Join(cfg.srcDir, name)
+		if !hasGoSources(pkgDir) {
+			return nil, fmt.Errorf("plugin source not found: %s", pkgDir)
+		}
+
+		output := filepath.Join(cfg.outDir, name+".so")
+		buildArgs := append([]string{"build", "-buildmode=plugin"}, flags...)
+		buildArgs = append(buildArgs, "-o", output, "./"+filepath.ToSlash(filepath.Clean(pkgDir)))
+
+		fmt.Printf("Building plugin: %s\n", name)
+		cmd := exec.Command("go", buildArgs...)
+		cmd.Env = append(os.Environ(), env...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("failed to build plugin %s: %w", name, err)
+		}
+		fmt.Printf("✓ %s.so built successfully\n", name)
+
+		built = append(built, output)
+	}
+	return built, nil
+}
+
+// toolchainPattern 只接受正式发布的工具链版本，开发版无法通过GOTOOLCHAIN选择
+var toolchainPattern = regexp.MustCompile(`^go\d+\.\d+(\.\d+)?$`)
+
+// hostBuildSettings 从当前程序的构建信息中提取编译插件所需的环境变量和参数
+// 插件必须与宿主使用相同的Go版本、构建标签和编译参数，否则plugin.Open会失败
+func hostBuildSettings(info *debug.BuildInfo) (env []string, flags []string, err error) {
+	if toolchainPattern.MatchString(info.GoVersion) {
+		env = append(env, "GOTOOLCHAIN="+info.GoVersion)
+	}
+
+	for _, s := range info.Settings {
+		switch {
+		case s.Key == "CGO_ENABLED" && s.Value != "1":
+			return nil, nil, errors.New("claude-plugin was built with CGO_ENABLED=0 and cannot load plugins; rebuild it with cgo enabled")
+		case s.Key == "-trimpath" || s.Key == "-race" || s.Key == "-msan" || s.Key == "-asan":
+			if s.Value == "true" {
+				flags = append(flags, s.Key)
+			}
+		case s.Key == "-tags" || s.Key == "-gcflags" || s.Key == "-asmflags":
+			flags = append(flags, s.Key+"="+s.Value)
+		case strings.HasPrefix(s.Key, "CGO_") || strings.HasPrefix(s.Key, "GO"):
+			env = append(env, s.Key+"="+s.Value)
+		}
+	}
+	return env, flags, nil
+}
+
+// discoverPlugins 返回srcDir下所有包含Go源码的插件目录名
+func discoverPlugins(srcDir string) ([]string, error) {
+	entries, err := os.ReadDir(srcDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin source directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && hasGoSources(filepath.Join(srcDir, entry.Name())) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func hasGoSources(dir string) bool {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, file := range files {
+		if !strings.HasSuffix(file, "_test.go") {
+			return true
+		}
+	}
+	return false
+}
+
+// installFile 先写入目标目录中的临时文件再重命名，避免正在运行的hook读到不完整的插件
+func installFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0755); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), dst)
+}
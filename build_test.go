@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime/debug"
+	"testing"
+)
+
+func TestHostBuildSettings(t *testing.T) {
+	info := &debug.BuildInfo{
+		GoVersion: "go1.22.5",
+		Settings: []debug.BuildSetting{
+			{Key: "-buildmode", Value: "exe"},
+			{Key: "-compiler", Value: "gc"},
+			{Key: "-tags", Value: "netgo"},
+			{Key: "-trimpath", Value: "true"},
+			{Key: "-ldflags", Value: "-s -w"},
+			{Key: "CGO_ENABLED", Value: "1"},
+			{Key: "GOARCH", Value: "amd64"},
+			{Key: "GOOS", Value: "linux"},
+			{Key: "GOAMD64", Value: "v3"},
+			{Key: "vcs", Value: "git"},
+		},
+	}
+
+	env, flags, err := hostBuildSettings(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantEnv := []string{"GOTOOLCHAIN=go1.22.5", "CGO_ENABLED=1", "GOARCH=amd64", "GOOS=linux", "GOAMD64=v3"}
+	if !reflect.DeepEqual(env, wantEnv) {
+		t.Errorf("env = %v, want %v", env, wantEnv)
+	}
+	wantFlags := []string{"-tags=netgo", "-trimpath"}
+	if !reflect.DeepEqual(flags, wantFlags) {
+		t.Errorf("flags = %v, want %v", flags, wantFlags)
+	}
+}
+
+func TestHostBuildSettingsWithoutCgo(t *testing.T) {
+	info := &debug.BuildInfo{
+		GoVersion: "go1.22.5",
+		Settings:  []debug.BuildSetting{{Key: "CGO_ENABLED", Value: "0"}},
+	}
+	if _, _, err := hostBuildSettings(info); err == nil {
+		t.Fatal("expected error for host built without cgo")
+	}
+}
+
+func TestHostBuildSettingsDevelToolchain(t *testing.T) {
+	env, _, err := hostBuildSettings(&debug.BuildInfo{GoVersion: "devel go1.23-abcdef"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(env) != 0 {
+		t.Errorf("env = %v, want no GOTOOLCHAIN for devel versions", env)
+	}
+}
+
+func TestInstallFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.so")
+	dst := filepath.Join(dir, "hooks", "env.so")
+	if err := os.WriteFile(src, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dst, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := installFile(src, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new" {
+		t.Errorf("installed content = %q, want %q", data, "new")
+	}
+	entries, _ := os.ReadDir(filepath.Dir(dst))
+	if len(entries) != 1 {
+		t.Errorf("expected no leftover temp files, found %d entries", len(entries))
+	}
+}
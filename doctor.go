@@ -0,0 +1,276 @@
+package main
+
+import (
+	"claude-hooks/types"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// doctorSettingsFiles 会被检查的settings文件，按Claude Code的加载顺序排列
+func doctorSettingsFiles() []string {
+	files := []string{
+		"./.claude/settings.json",
+		"./.claude/settings.local.json",
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		files = append([]string{filepath.Join(homeDir, ".claude", "settings.json")}, files...)
+	}
+	return files
+}
+
+type diagnosis struct {
+	level   string // ok, warn, fail
+	message string
+	fix     string
+}
+
+type doctorReport struct {
+	diagnoses []diagnosis
+}
+
+func (r *doctorReport) ok(format string, args ...any) {
+	r.diagnoses = append(r.diagnoses, diagnosis{level: "ok", message: fmt.Sprintf(format, args...)})
+}
+
+func (r *doctorReport) warn(fix string, format string, args ...any) {
+	r.diagnoses = append(r.diagnoses, diagnosis{level: "warn", message: fmt.Sprintf(format, args...), fix: fix})
+}
+
+func (r *doctorReport) fail(fix string, format string, args ...any) {
+	r.diagnoses = append(r.diagnoses, diagnosis{level: "fail", message: fmt.Sprintf(format, args...), fix: fix})
+}
+
+func (r *doctorReport) failures() int {
+	n := 0
+	for _, d := range r.diagnoses {
+		if d.level == "fail" {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *doctorReport) print() {
+	symbols := map[string]string{"ok": "✓", "warn": "!", "fail": "✗"}
+	for _, d := range r.diagnoses {
+		fmt.Printf("%s %s\n", symbols[d.level], d.message)
+		if d.fix != "" {
+			fmt.Printf("    修复: %s\n", d.fix)
+		}
+	}
+}
+
+// doctorSettings 只解析doctor需要的字段，以兼容所有hook类型
+type doctorSettings struct {
+	Hooks map[string][]HookConfig `json:"hooks"`
+}
+
+func handleDoctorCommand() error {
+	report := &doctorReport{}
+
+	checkHookDir(report)
+
+	pluginPaths := make(map[string]bool)
+	for _, path := range doctorSettingsFiles() {
+		checkSettingsFile(report, path, pluginPaths)
+	}
+
+	paths := make([]string, 0, len(pluginPaths))
+	for path := range pluginPaths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		checkPlugin(report, path)
+	}
+
+	report.print()
+
+	if n := report.failures(); n > 0 {
+		fmt.Printf("\n发现 %d 个问题\n", n)
+		os.Exit(types.ExitCodeError)
+	}
+	fmt.Println("\n未发现问题")
+	return nil
+}
+
+func checkHookDir(report *doctorReport) {
+	dir, err := defaultPluginDir()
+	if err != nil {
+		report.fail("设置HOME环境变量", "无法确定用户主目录: %v", err)
+		return
+	}
+
+	info, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		report.warn("运行 make build-plugin 编译并安装插件", "插件目录 %s 不存在", dir)
+		return
+	}
+	if err != nil {
+		report.fail(fmt.Sprintf("检查 %s 的权限", dir), "无法访问插件目录 %s: %v", dir, err)
+		return
+	}
+	if !info.IsDir() {
+		report.fail(fmt.Sprintf("删除 %s 并重新运行 make build-plugin", dir), "%s 不是目录", dir)
+		return
+	}
+	if info.Mode().Perm()&0o002 != 0 {
+		report.fail(fmt.Sprintf("chmod o-w %s", dir), "插件目录 %s 对所有用户可写，任何人都可以替换插件", dir)
+	} else {
+		report.ok("插件目录 %s 权限正常", dir)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.so"))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		if info.Mode().Perm()&0o002 != 0 {
+			report.fail(fmt.Sprintf("chmod o-w %s", file), "插件 %s 对所有用户可写", file)
+		}
+	}
+}
+
+func checkSettingsFile(report *doctorReport, path string, pluginPaths map[string]bool) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		report.fail(fmt.Sprintf("检查 %s 的权限", path), "无法读取 %s: %v", path, err)
+		return
+	}
+
+	var settings doctorSettings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		report.fail("修正JSON语法错误，或删除该文件后重新运行 configure", "无法解析 %s: %v", path, err)
+		return
+	}
+	report.ok("%s 解析成功", path)
+
+	events := make([]string, 0, len(settings.Hooks))
+	for event := range settings.Hooks {
+		events = append(events, event)
+	}
+	sort.Strings(events)
+
+	for _, event := range events {
+		for _, config := range settings.Hooks[event] {
+			checkMatcher(report, path, event, config.Matcher)
+			for _, hook := range config.Hooks {
+				checkHookCommand(report, path, event, hook, pluginPaths)
+			}
+		}
+	}
+}
+
+func checkMatcher(report *doctorReport, path, event, matcher string) {
+	if matcher == "" || matcher == "*" {
+		return
+	}
+	if _, err := regexp.Compile(matcher); err != nil {
+		report.fail("修正正则表达式，或重新运行 configure", "%s 中 %s 的matcher %q 不是有效的正则表达式: %v", path, event, matcher, err)
+	}
+}
+
+func checkHookCommand(report *doctorReport, path, event string, hook HookEntry, pluginPaths map[string]bool) {
+	fields := strings.Fields(hook.Command)
+	if hook.Type != "command" || len(fields) == 0 || filepath.Base(fields[0]) != "claude-plugin" {
+		return
+	}
+
+	checkBinary(report, fields[0])
+
+	cfg, err := parseArgs(fields[1:])
+	if err != nil {
+		report.fail("运行 make build-plugin 安装插件，或修正settings中的命令",
+			"%s 中 %s 的命令 %q 无效: %v", path, event, hook.Command, firstLine(err.Error()))
+		return
+	}
+	for _, pluginPath := range cfg.pluginPaths {
+		pluginPaths[pluginPath] = true
+	}
+}
+
+// checkedBinaries 避免同一个命令重复报告
+var checkedBinaries = make(map[string]bool)
+
+func checkBinary(report *doctorReport, name string) {
+	if checkedBinaries[name] {
+		return
+	}
+	checkedBinaries[name] = true
+
+	resolved, err := exec.LookPath(name)
+	if err != nil {
+		report.fail("运行 make build 并确保 ~/.local/bin 在PATH中", "settings中的命令 %s 不在PATH中", name)
+		return
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		report.warn("", "无法确定当前程序路径: %v", err)
+		return
+	}
+
+	if sameFile(resolved, self) {
+		report.ok("%s 指向当前程序 %s", name, self)
+	} else {
+		report.warn("运行 make build 重新安装，或调整PATH顺序",
+			"settings中的 %s 解析为 %s，与当前运行的 %s 不同", name, resolved, self)
+	}
+}
+
+func sameFile(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return os.SameFile(infoA, infoB)
+}
+
+func checkPlugin(report *doctorReport, path string) {
+	pm := types.NewPluginManager("")
+	if err := pm.LoadPlugin(path); err != nil {
+		fix := "运行 make build-plugin 重新编译并安装插件"
+		if msg := err.Error(); strings.Contains(msg, "different version of package") || strings.Contains(msg, "API version") {
+			fix = "插件与claude-plugin的源码或Go版本不一致，使用相同版本运行 make install 重新编译"
+		}
+		report.fail(fix, "无法加载插件 %s: %v", path, err)
+		return
+	}
+	defer pm.Shutdown()
+
+	plugin, exists := pm.GetPlugin(filepath.Base(path))
+	if !exists {
+		return
+	}
+	metadata := plugin.GetMetadata()
+
+	if metadata.APIVersion == 0 {
+		report.warn("在GetMetadata中设置 APIVersion: types.APIVersion", "插件 %s 未声明接口版本", path)
+	} else {
+		report.ok("插件 %s 加载成功 (API version %d)", path, metadata.APIVersion)
+	}
+
+	for _, tool := range metadata.Requires {
+		if _, err := exec.LookPath(tool); err != nil {
+			report.fail(fmt.Sprintf("安装 %s 并确保其在PATH中", tool), "插件 %s 依赖的命令 %s 不存在", path, tool)
+		}
+	}
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
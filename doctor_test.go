@@ -0,0 +1,35 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckSettingsFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		failures int
+	}{
+		{"invalid json", `{"hooks":`, 1},
+		{"invalid matcher", `{"hooks":{"PreToolUse":[{"matcher":"Read(","hooks":[]}]}}`, 1},
+		{"valid matchers", `{"hooks":{"PreToolUse":[{"matcher":"Read|Write","hooks":[]}],"Stop":[{"matcher":"","hooks":[]}]}}`, 0},
+		{"foreign commands ignored", `{"hooks":{"Stop":[{"matcher":"","hooks":[{"type":"command","command":"echo done"}]}]}}`, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "settings.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			report := &doctorReport{}
+			checkSettingsFile(report, path, make(map[string]bool))
+			if got := report.failures(); got != tt.failures {
+				t.Errorf("failures = %d, want %d: %+v", got, tt.failures, report.diagnoses)
+			}
+		})
+	}
+}
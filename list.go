@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bytes"
+	"claude-hooks/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// pluginDetails 插件的完整信息，用于list --json和info
+type pluginDetails struct {
+	Name         string           `json:"name"`
+	Path         string           `json:"path"`
+	Description  string           `json:"description,omitempty"`
+	Version      string           `json:"version,omitempty"`
+	APIVersion   int              `json:"apiVersion,omitempty"`
+	Events       []string         `json:"events"`
+	Matchers     []matcherDetails `json:"matchers"`
+	Requires     []string         `json:"requires,omitempty"`
+	ConfigSchema json.RawMessage  `json:"configSchema,omitempty"`
+	Error        string           `json:"error,omitempty"`
+}
+
+type matcherDetails struct {
+	Event           string   `json:"event"`
+	Pattern         string   `json:"pattern"`
+	ID              string   `json:"id,omitempty"`
+	FileGlobs       []string `json:"fileGlobs,omitempty"`
+	PathPrefixes    []string `json:"pathPrefixes,omitempty"`
+	CommandPatterns []string `json:"commandPatterns,omitempty"`
+	MCPServers      []string `json:"mcpServers,omitempty"`
+}
+
+func handleListCommand(pm *types.PluginManager, cfg *config, loadErrors []*pluginLoadError) error {
+	if cfg.jsonOutput {
+		return printJSON(collectPluginDetails(pm, loadErrors))
+	}
+
+	fmt.Print(listPlugins(pm, loadErrors).Data)
+	return nil
+}
+
+func handleInfoCommand(pm *types.PluginManager, cfg *config, loadErrors []*pluginLoadError) error {
+	details := collectPluginDetails(pm, loadErrors)
+	if len(details) == 0 {
+		return errors.New("info requires at least one plugin")
+	}
+
+	if cfg.jsonOutput {
+		return printJSON(details)
+	}
+
+	for i, d := range details {
+		if i > 0 {
+			fmt.Println()
+		}
+		writePluginDetails(os.Stdout, d)
+	}
+	return nil
+}
+
+func collectPluginDetails(pm *types.PluginManager, loadErrors []*pluginLoadError) []pluginDetails {
+	plugins := pm.ListPlugins()
+	sortPluginInfos(plugins)
+
+	details := make([]pluginDetails, 0, len(plugins)+len(loadErrors))
+	for _, info := range plugins {
+		plugin, exists := pm.GetPlugin(info.Name)
+		if !exists {
+			continue
+		}
+		metadata := plugin.GetMetadata()
+
+		details = append(details, pluginDetails{
+			Name:         info.Name,
+			Path:         info.Path,
+			Description:  metadata.Description,
+			Version:      metadata.Version,
+			APIVersion:   metadata.APIVersion,
+			Events:       types.ImplementedEvents(plugin),
+			Matchers:     toMatcherDetails(metadata.AllMatchers()),
+			Requires:     metadata.Requires,
+			ConfigSchema: metadata.ConfigSchema,
+		})
+	}
+
+	for _, loadErr := range loadErrors {
+		path, err := filepath.Abs(loadErr.path)
+		if err != nil {
+			path = loadErr.path
+		}
+		details = append(details, pluginDetails{
+			Name:     filepath.Base(loadErr.path),
+			Path:     path,
+			Events:   []string{},
+			Matchers: []matcherDetails{},
+			Error:    loadErr.err.Error(),
+		})
+	}
+	return details
+}
+
+func writePluginDetails(w io.Writer, d pluginDetails) {
+	fmt.Fprintf(w, "Name:         %s\n", d.Name)
+	fmt.Fprintf(w, "Path:         %s\n", displayPath(d.Path))
+	if d.Error != "" {
+		fmt.Fprintf(w, "Error:        %s\n", d.Error)
+		return
+	}
+	fmt.Fprintf(w, "Description:  %s\n", d.Description)
+	fmt.Fprintf(w, "Version:      %s\n", valueOrNone(d.Version))
+	if d.APIVersion != 0 {
+		fmt.Fprintf(w, "API version:  %d\n", d.APIVersion)
+	} else {
+		fmt.Fprintf(w, "API version:  %s\n", valueOrNone(""))
+	}
+	fmt.Fprintf(w, "Events:       %s\n", valueOrNone(strings.Join(d.Events, ", ")))
+	for _, event := range d.Events {
+		for _, m := range d.Matchers {
+			if m.Event == event {
+				fmt.Fprintf(w, "Matcher:      %s\n", formatMatcher(m))
+			}
+		}
+	}
+	fmt.Fprintf(w, "Requires:     %s\n", valueOrNone(strings.Join(d.Requires, ", ")))
+	if len(d.ConfigSchema) == 0 {
+		fmt.Fprintf(w, "Config:       %s\n", valueOrNone(""))
+		return
+	}
+	var schema bytes.Buffer
+	if err := json.Indent(&schema, d.ConfigSchema, "  ", "  "); err != nil {
+		schema.Write(d.ConfigSchema)
+	}
+	fmt.Fprintf(w, "Config:\n  %s\n", schema.String())
+}
+
+func toMatcherDetails(matchers []types.Matcher) []matcherDetails {
+	details := make([]matcherDetails, 0, len(matchers))
+	for _, m := range matchers {
+		details = append(details, matcherDetails{
+			Event:           m.Event,
+			Pattern:         m.Pattern,
+			ID:              m.ID,
+			FileGlobs:       m.FileGlobs,
+			PathPrefixes:    m.PathPrefixes,
+			CommandPatterns: m.CommandPatterns,
+			MCPServers:      m.MCPServers,
+		})
+	}
+	return details
+}
+
+func formatMatcher(m matcherDetails) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %s", m.Event, m.Pattern)
+	if m.ID != "" {
+		fmt.Fprintf(&sb, " (%s)", m.ID)
+	}
+
+	conditions := []struct {
+		name   string
+		values []string
+	}{
+		{"files", m.FileGlobs},
+		{"under", m.PathPrefixes},
+		{"command", m.CommandPatterns},
+		{"mcp", m.MCPServers},
+	}
+	for _, c := range conditions {
+		if len(c.values) > 0 {
+			fmt.Fprintf(&sb, " %s=%s", c.name, strings.Join(c.values, ","))
+		}
+	}
+	return sb.String()
+}
+
+func valueOrNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+func printJSON(v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal output: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+func sortPluginInfos(plugins []types.PluginInfo) {
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Name < plugins[j].Name
+	})
+}
+
+// displayPath 将用户主目录路径替换为 ~
+func displayPath(path string) string {
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		if strings.HasPrefix(path, homeDir) {
+			return "~" + strings.TrimPrefix(path, homeDir)
+		}
+	}
+	return path
+}
+
+func listPlugins(pm *types.PluginManager, loadErrors []*pluginLoadError) types.Result {
+	plugins := pm.ListPlugins()
+	if len(plugins) == 0 && len(loadErrors) == 0 {
+		return types.NewSuccess("No plugins loaded.\n")
+	}
+	sortPluginInfos(plugins)
+
+	var sb strings.Builder
+	sb.WriteString("Loaded plugins:\n")
+
+	for _, info := range plugins {
+		plugin, exists := pm.GetPlugin(info.Name)
+		if !exists {
+			continue
+		}
+
+		writePluginInfo(&sb, info, plugin)
+	}
+
+	for _, loadErr := range loadErrors {
+		fmt.Fprintf(&sb, "\n• Plugin: %s (%s)\n", filepath.Base(loadErr.path), displayPath(loadErr.path))
+		fmt.Fprintf(&sb, "  Error: %v\n", loadErr.err)
+	}
+
+	return types.NewSuccess(sb.String())
+}
+
+func writePluginInfo(sb *strings.Builder, info types.PluginInfo, plugin types.IPlugin) {
+	metadata := plugin.GetMetadata()
+
+	fmt.Fprintf(sb, "\n• Plugin: %s (%s)\n", info.Name, displayPath(info.Path))
+	fmt.Fprintf(sb, "  Description: %s\n", info.Description)
+	if metadata.Version != "" {
+		fmt.Fprintf(sb, "  Version: %s\n", metadata.Version)
+	}
+	fmt.Fprintf(sb, "  Events: %s\n", valueOrNone(strings.Join(types.ImplementedEvents(plugin), ", ")))
+	sb.WriteString("  Matchers:\n")
+
+	matchers := toMatcherDetails(metadata.AllMatchers())
+	for _, m := range matchers {
+		fmt.Fprintf(sb, "    %s\n", formatMatcher(m))
+	}
+
+	if len(matchers) == 0 {
+		sb.WriteString("    No matchers configured\n")
+	}
+}
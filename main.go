@@ -16,16 +16,37 @@ func printHelp() {
 	fmt.Println()
 	fmt.Println("USAGE:")
 	fmt.Println("  claude-plugin [OPTIONS] <plugins...> <command>")
+	fmt.Println("  claude-plugin new <name> [--events <events>] [--matcher <regex>] [--kind so|exec]")
+	fmt.Println("  claude-plugin build|install [names...] [--src <dir>] [--out <dir>] [--hooks-dir <dir>]")
 	fmt.Println()
 	fmt.Println("COMMANDS:")
 	fmt.Println("  list         列出已加载的插件信息")
+	fmt.Println("  info         显示插件的完整元数据")
 	fmt.Println("  execute      执行插件（从stdin读取JSON输入）")
 	fmt.Println("  configure    根据指定插件自动配置hooks到settings.local.json")
+	fmt.Println("  doctor       诊断安装及settings中的hooks配置")
+	fmt.Println("  new          在plugins/<name>/下生成插件骨架、测试和夹具")
+	fmt.Println("  build        使用与claude-plugin相同的工具链和编译参数编译插件")
+	fmt.Println("  install      编译插件并安装到hooks目录")
 	fmt.Println()
 	fmt.Println("OPTIONS:")
 	fmt.Println("  --dir <path>  指定插件目录路径")
+	fmt.Println("  --json        list/info以JSON格式输出")
+	fmt.Println("  --output-mode json|exitcode")
+	fmt.Println("                execute的输出方式：json将决策以JSON写到stdout；exitcode只使用退出码和stderr，默认json")
 	fmt.Println("  --help, -h    显示此帮助信息")
 	fmt.Println()
+	fmt.Println("NEW OPTIONS:")
+	fmt.Println("  --events <list>   逗号分隔的hook事件，默认PreToolUse")
+	fmt.Println("  --matcher <regex> PreToolUse/PostToolUse的工具匹配器，默认*")
+	fmt.Println("  --kind so|exec    so: 由claude-plugin加载；exec: 编译为独立的hook命令，默认so")
+	fmt.Println("  --dir <path>      插件源码目录，默认plugins")
+	fmt.Println()
+	fmt.Println("BUILD/INSTALL OPTIONS:")
+	fmt.Println("  --src <dir>        插件源码目录，默认plugins")
+	fmt.Println("  --out <dir>        编译输出目录，默认./.claude/hooks")
+	fmt.Println("  --hooks-dir <dir>  install的目标目录，默认~/.claude/hooks")
+	fmt.Println()
 	fmt.Println("PLUGIN SPECIFICATION:")
 	fmt.Println("  - 可以直接指定.so文件的完整路径")
 	fmt.Println("  - 可以只指定插件名称，会从以下位置查找：")
@@ -42,9 +63,24 @@ func printHelp() {
 	fmt.Println("  # 从默认路径加载插件")
 	fmt.Println("  claude-plugin env announce execute")
 	fmt.Println()
+	fmt.Println("  # 以JSON格式列出插件，供编辑器和脚本使用")
+	fmt.Println("  claude-plugin env gofmt list --json")
+	fmt.Println()
+	fmt.Println("  # 查看插件的完整元数据")
+	fmt.Println("  claude-plugin info env")
+	fmt.Println()
 	fmt.Println("  # 配置插件到settings.local.json")
 	fmt.Println("  claude-plugin gofmt env configure")
 	fmt.Println()
+	fmt.Println("  # 生成新插件")
+	fmt.Println("  claude-plugin new guard --events PreToolUse,Stop --matcher 'Bash'")
+	fmt.Println()
+	fmt.Println("  # 编译并安装所有插件")
+	fmt.Println("  claude-plugin install")
+	fmt.Println()
+	fmt.Println("  # 诊断hooks为何没有生效")
+	fmt.Println("  claude-plugin doctor")
+	fmt.Println()
 	fmt.Println("  # 混合使用")
 	fmt.Println("  claude-plugin --dir ./plugins env announce list")
 }
@@ -76,27 +112,43 @@ func main() {
 }
 
 func run(args []string) error {
+	// 以下命令操作插件源码，不需要加载插件，参数格式也不同
+	switch args[0] {
+	case "new":
+		return handleNewCommand(args[1:])
+	case "build":
+		return handleBuildCommand(args[1:])
+	case "install":
+		return handleInstallCommand(args[1:])
+	}
+
 	config, err := parseArgs(args)
 	if err != nil {
 		return err
 	}
 
 	pm := types.NewPluginManager("")
-	if err := loadPlugins(pm, config.pluginPaths); err != nil {
-		return err
+	loadErrors := loadPlugins(pm, config.pluginPaths)
+
+	// list和info需要展示加载失败的插件，其他命令遇到加载错误直接失败
+	if len(loadErrors) > 0 && config.command != "list" && config.command != "info" {
+		return loadErrors[0]
 	}
 
-	return executeCommand(pm, config.command)
+	return executeCommand(pm, config, loadErrors)
 }
 
 type config struct {
 	pluginPaths []string
 	command     string
+	jsonOutput  bool
+	outputMode  types.OutputMode
 }
 
 func parseArgs(args []string) (*config, error) {
 	cfg := &config{
 		pluginPaths: make([]string, 0),
+		outputMode:  types.OutputModeJSON,
 	}
 
 	for i := 0; i < len(args); i++ {
@@ -107,6 +159,20 @@ func parseArgs(args []string) (*config, error) {
 			// 帮助标志在main函数中已经处理，这里跳过
 			continue
 
+		case arg == "--json":
+			cfg.jsonOutput = true
+
+		case arg == "--output-mode":
+			if i+1 >= len(args) {
+				return nil, errors.New("--output-mode requires json or exitcode")
+			}
+			i++
+			mode, err := types.ParseOutputMode(args[i])
+			if err != nil {
+				return nil, err
+			}
+			cfg.outputMode = mode
+
 		case arg == "--dir":
 			if i+1 >= len(args) {
 				return nil, errors.New("--dir requires a directory path")
@@ -138,7 +204,7 @@ func parseArgs(args []string) (*config, error) {
 	}
 
 	if cfg.command == "" {
-		return nil, errors.New("no command specified (list or execute)\n\nUse --help for usage information")
+		return nil, errors.New("no command specified (list, execute, configure or doctor)\n\nUse --help for usage information")
 	}
 
 	return cfg, nil
@@ -151,8 +217,14 @@ func parsePluginsFromDir(args []string, startIdx int, dir string, cfg *config) i
 		nextArg := args[i]
 
 		if isCommand(nextArg) {
+			// 命令之后的插件（如 info <plugin>）同样从指定目录查找
 			cfg.command = nextArg
-			return i
+			continue
+		}
+
+		if strings.HasPrefix(nextArg, "-") {
+			// 选项交给parseArgs处理
+			return i - 1
 		}
 
 		// 先尝试从指定目录查找
@@ -172,15 +244,21 @@ func parsePluginsFromDir(args []string, startIdx int, dir string, cfg *config) i
 	return i
 }
 
-func findPluginInDefaultPath(pluginName string) string {
-	// 获取用户主目录
+// defaultPluginDir 返回默认插件目录 ~/.claude/hooks
+func defaultPluginDir() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return ""
+		return "", err
 	}
+	return filepath.Join(homeDir, ".claude", "hooks"), nil
+}
 
+func findPluginInDefaultPath(pluginName string) string {
 	// 默认插件路径
-	defaultPath := filepath.Join(homeDir, ".claude", "hooks")
+	defaultPath, err := defaultPluginDir()
+	if err != nil {
+		return ""
+	}
 
 	// 构建可能的插件文件名
 	possibleNames := []string{
@@ -202,268 +280,65 @@ func findPluginInDefaultPath(pluginName string) string {
 }
 
 func isCommand(arg string) bool {
-	return arg == "list" || arg == "execute" || arg == "configure"
+	return arg == "list" || arg == "info" || arg == "execute" || arg == "configure" || arg == "doctor"
+}
+
+// pluginLoadError 记录加载失败的插件
+type pluginLoadError struct {
+	path string
+	err  error
+}
+
+func (e *pluginLoadError) Error() string {
+	return fmt.Sprintf("failed to load plugin %s: %v", e.path, e.err)
+}
+
+func (e *pluginLoadError) Unwrap() error {
+	return e.err
 }
 
-func loadPlugins(pm *types.PluginManager, paths []string) error {
+func loadPlugins(pm *types.PluginManager, paths []string) []*pluginLoadError {
+	var loadErrors []*pluginLoadError
 	for _, path := range paths {
 		if err := pm.LoadPlugin(path); err != nil {
-			return fmt.Errorf("failed to load plugin %s: %w", path, err)
+			loadErrors = append(loadErrors, &pluginLoadError{path: path, err: err})
 		}
 	}
-	return nil
+	return loadErrors
 }
 
-func executeCommand(pm *types.PluginManager, command string) error {
-	switch command {
+func executeCommand(pm *types.PluginManager, cfg *config, loadErrors []*pluginLoadError) error {
+	switch cfg.command {
 	case "list":
-		return handleListCommand(pm)
+		return handleListCommand(pm, cfg, loadErrors)
+	case "info":
+		return handleInfoCommand(pm, cfg, loadErrors)
 	case "execute":
-		return handleExecuteCommand(pm)
+		return handleExecuteCommand(pm, cfg)
 	case "configure":
 		return handleConfigureCommand(pm)
+	case "doctor":
+		return handleDoctorCommand()
 	default:
-		return fmt.Errorf("unknown command: %q", command)
+		return fmt.Errorf("unknown command: %q", cfg.command)
 	}
 }
 
-func handleListCommand(pm *types.PluginManager) error {
-	result := listPlugins(pm)
-	result.ExitWithMessage()
-	return nil
-}
-
-func handleExecuteCommand(pm *types.PluginManager) error {
-	plugins := pm.Plugins()
-	if len(plugins) == 0 {
-		return errors.New("no plugins loaded")
-	}
-
-	input, err := readAndParseInput()
+func handleExecuteCommand(pm *types.PluginManager, cfg *config) error {
+	data, err := readStdin()
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to read stdin: %w", err)
 	}
 
-	hookType, err := extractHookType(input)
+	result, err := pm.RunHook([]byte(data))
 	if err != nil {
 		return err
 	}
 
-	inputData, err := json.Marshal(input)
-	if err != nil {
-		return fmt.Errorf("failed to marshal input: %w", err)
-	}
-
-	var lastResult *types.Result
-	for _, plugin := range plugins {
-		result := executePlugin(hookType, string(inputData), plugin)
-		if !result.IsSuccess() {
-			result.ExitWithMessage()
-			return nil
-		}
-		lastResult = &result
-	}
-
-	if lastResult != nil {
-		lastResult.ExitWithMessage()
-	}
+	result.Exit(cfg.outputMode)
 	return nil
 }
 
-func readAndParseInput() (map[string]any, error) {
-	data, err := readStdin()
-	if err != nil {
-		return nil, fmt.Errorf("failed to read stdin: %w", err)
-	}
-
-	var input map[string]any
-	if err := json.Unmarshal([]byte(data), &input); err != nil {
-		return nil, fmt.Errorf("invalid JSON input: %w", err)
-	}
-
-	return input, nil
-}
-
-func extractHookType(input map[string]any) (string, error) {
-	hookType, ok := input["hook_event_name"].(string)
-	if !ok {
-		return "", errors.New("missing or invalid hook_event_name")
-	}
-	return hookType, nil
-}
-
-func listPlugins(pm *types.PluginManager) types.Result {
-	plugins := pm.ListPlugins()
-	if len(plugins) == 0 {
-		return types.NewSuccess("No plugins loaded.\n")
-	}
-
-	var sb strings.Builder
-	sb.WriteString("Loaded plugins:\n")
-
-	for _, info := range plugins {
-		plugin, exists := pm.GetPlugin(info.Name)
-		if !exists {
-			continue
-		}
-
-		writePluginInfo(&sb, info, plugin.GetMetadata())
-	}
-
-	return types.NewSuccess(sb.String())
-}
-
-func writePluginInfo(sb *strings.Builder, info types.PluginInfo, metadata types.PluginMetadata) {
-	// 将用户主目录路径替换为 ~
-	displayPath := info.Path
-	if homeDir, err := os.UserHomeDir(); err == nil {
-		if strings.HasPrefix(displayPath, homeDir) {
-			displayPath = "~" + strings.TrimPrefix(displayPath, homeDir)
-		}
-	}
-
-	fmt.Fprintf(sb, "\n• Plugin: %s (%s)\n", info.Name, displayPath)
-	fmt.Fprintf(sb, "  Description: %s\n", info.Description)
-	sb.WriteString("  Matchers:\n")
-
-	matchers := []struct {
-		name  string
-		value string
-	}{
-		{"PreToolUse", metadata.Matcher.PreToolUse},
-		{"PostToolUse", metadata.Matcher.PostToolUse},
-	}
-
-	hasMatchers := false
-	for _, m := range matchers {
-		if m.value != "" {
-			fmt.Fprintf(sb, "    %s: %s\n", m.name, m.value)
-			hasMatchers = true
-		}
-	}
-
-	if !hasMatchers {
-		sb.WriteString("    No matchers configured\n")
-	}
-}
-
-func executePlugin(hookType string, inputData string, plugin types.IPlugin) types.Result {
-	handler, ok := hookHandlers[hookType]
-	if !ok {
-		return types.NewError(fmt.Sprintf("unknown hook type: %s", hookType))
-	}
-	return handler(inputData, plugin)
-}
-
-var hookHandlers = map[string]func(string, types.IPlugin) types.Result{
-	"PreToolUse":   handlePreToolUse,
-	"PostToolUse":  handlePostToolUse,
-	"Notification": handleNotification,
-	"Stop":         handleStop,
-	"SubagentStop": handleSubagentStop,
-}
-
-func handlePreToolUse(inputData string, plugin types.IPlugin) types.Result {
-	var input types.ToolInput
-	if err := json.Unmarshal([]byte(inputData), &input); err != nil {
-		return types.NewError(fmt.Sprintf("invalid PreToolUse input: %v", err))
-	}
-
-	result, err := plugin.PreToolUse(input)
-	return processPluginResult(withDefault(result), err)
-}
-
-func handlePostToolUse(inputData string, plugin types.IPlugin) types.Result {
-	var input types.PostToolUseInput
-	if err := json.Unmarshal([]byte(inputData), &input); err != nil {
-		return types.NewError(fmt.Sprintf("invalid PostToolUse input: %v", err))
-	}
-
-	result, err := plugin.PostToolUse(input)
-	return processPluginResult(withDefault(result), err)
-}
-
-func handleNotification(inputData string, plugin types.IPlugin) types.Result {
-	var input types.NotificationInput
-	if err := json.Unmarshal([]byte(inputData), &input); err != nil {
-		return types.NewError(fmt.Sprintf("invalid Notification input: %v", err))
-	}
-
-	result, err := plugin.Notification(input)
-	return processPluginResult(result, err)
-}
-
-func handleStop(inputData string, plugin types.IPlugin) types.Result {
-	var input types.StopInput
-	if err := json.Unmarshal([]byte(inputData), &input); err != nil {
-		return types.NewError(fmt.Sprintf("invalid Stop input: %v", err))
-	}
-
-	result, err := plugin.Stop(input)
-	return processPluginResult(withDefault(result), err)
-}
-
-func handleSubagentStop(inputData string, plugin types.IPlugin) types.Result {
-	var input types.SubagentStopInput
-	if err := json.Unmarshal([]byte(inputData), &input); err != nil {
-		return types.NewError(fmt.Sprintf("invalid SubagentStop input: %v", err))
-	}
-
-	result, err := plugin.SubagentStop(input)
-	return processPluginResult(result, err)
-}
-
-func processPluginResult(result any, err error) types.Result {
-	if err != nil {
-		return types.NewError(err.Error())
-	}
-
-	if result == nil {
-		return types.NewSuccess("")
-	}
-
-	data, err := json.Marshal(result)
-	if err != nil {
-		return types.NewError(fmt.Sprintf("failed to marshal result: %v", err))
-	}
-
-	if blockResult := checkBlockDecision(data); blockResult != nil {
-		return *blockResult
-	}
-
-	return types.NewSuccess(string(data))
-}
-
-func checkBlockDecision(data []byte) *types.Result {
-	var m map[string]any
-	if err := json.Unmarshal(data, &m); err != nil {
-		return nil
-	}
-
-	decision, ok := m["decision"].(string)
-	if !ok || decision != "block" {
-		return nil
-	}
-
-	reason, _ := m["reason"].(string)
-	return &types.Result{
-		Code:  types.ExitCodeBlockingError,
-		Error: fmt.Sprintf("%s\n", reason),
-	}
-}
-
-func withDefault[T any, U interface {
-	*T
-	Default()
-}](input U) U {
-	if input != nil {
-		return input
-	}
-	var result U = new(T)
-	result.Default()
-	return result
-}
-
 func readStdin() (string, error) {
 	data, err := io.ReadAll(os.Stdin)
 	if err != nil {
@@ -479,10 +354,8 @@ type ClaudeSettings struct {
 		Deny  []string `json:"deny"`
 		Ask   []string `json:"ask"`
 	} `json:"permissions"`
-	Hooks struct {
-		PreToolUse  []HookConfig `json:"PreToolUse"`
-		PostToolUse []HookConfig `json:"PostToolUse"`
-	} `json:"hooks"`
+	// hook事件名 -> 匹配器配置
+	Hooks map[string][]HookConfig `json:"hooks"`
 }
 
 type HookConfig struct {
@@ -523,9 +396,19 @@ func updateSettingsFile(pm *types.PluginManager) error {
 	// 获取所有插件信息
 	plugins := pm.ListPlugins()
 
-	// 按hook类型组织插件
-	preToolUsePlugins := make(map[string][]string)  // matcher -> plugin names
-	postToolUsePlugins := make(map[string][]string) // matcher -> plugin names
+	// 按hook类型组织插件: event -> matcher -> plugin names
+	hookPlugins := make(map[string]map[string][]string)
+	addPlugin := func(event, matcher, name string) {
+		if hookPlugins[event] == nil {
+			hookPlugins[event] = make(map[string][]string)
+		}
+		for _, existing := range hookPlugins[event][matcher] {
+			if existing == name {
+				return
+			}
+		}
+		hookPlugins[event][matcher] = append(hookPlugins[event][matcher], name)
+	}
 
 	for _, info := range plugins {
 		plugin, exists := pm.GetPlugin(info.Name)
@@ -535,24 +418,27 @@ func updateSettingsFile(pm *types.PluginManager) error {
 
 		metadata := plugin.GetMetadata()
 
-		// 处理PreToolUse匹配器
-		if metadata.Matcher.PreToolUse != "" {
-			matcher := metadata.Matcher.PreToolUse
-			preToolUsePlugins[matcher] = append(preToolUsePlugins[matcher], info.Name)
-		}
+		// 只为插件实现了的事件注册hooks
+		for _, event := range types.ImplementedEvents(plugin) {
+			matchers := metadata.MatchersFor(event)
+			if len(matchers) == 0 {
+				// 工具事件必须声明匹配器才会注册，其他事件不需要matcher
+				if event == "PreToolUse" || event == "PostToolUse" {
+					continue
+				}
+				addPlugin(event, "", info.Name)
+				continue
+			}
 
-		// 处理PostToolUse匹配器
-		if metadata.Matcher.PostToolUse != "" {
-			matcher := metadata.Matcher.PostToolUse
-			postToolUsePlugins[matcher] = append(postToolUsePlugins[matcher], info.Name)
+			for _, matcher := range matchers {
+				addPlugin(event, matcher.Pattern, info.Name)
+			}
 		}
 	}
 
-	// 更新PreToolUse配置
-	settings.Hooks.PreToolUse = updateHookConfigs(settings.Hooks.PreToolUse, preToolUsePlugins)
-
-	// 更新PostToolUse配置
-	settings.Hooks.PostToolUse = updateHookConfigs(settings.Hooks.PostToolUse, postToolUsePlugins)
+	for event, matcherPlugins := range hookPlugins {
+		settings.Hooks[event] = updateHookConfigs(settings.Hooks[event], matcherPlugins)
+	}
 
 	// 保存配置
 	return saveSettings(settingsPath, settings)
@@ -566,8 +452,7 @@ func loadSettings(path string) (*ClaudeSettings, error) {
 		settings.Permissions.Allow = []string{}
 		settings.Permissions.Deny = []string{}
 		settings.Permissions.Ask = []string{}
-		settings.Hooks.PreToolUse = []HookConfig{}
-		settings.Hooks.PostToolUse = []HookConfig{}
+		settings.Hooks = make(map[string][]HookConfig)
 		return settings, nil
 	}
 
@@ -579,6 +464,9 @@ func loadSettings(path string) (*ClaudeSettings, error) {
 	if err := json.Unmarshal(data, settings); err != nil {
 		return nil, fmt.Errorf("failed to parse settings file: %w", err)
 	}
+	if settings.Hooks == nil {
+		settings.Hooks = make(map[string][]HookConfig)
+	}
 
 	return settings, nil
 }
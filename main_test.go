@@ -0,0 +1,75 @@
+package main
+
+import (
+	"claude-hooks/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseArgsWithDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"env.so", "gofmt.so"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		command    string
+		plugins    []string
+		jsonOutput bool
+	}{
+		{"plugins before command", []string{"--dir", dir, "env", "gofmt", "list"}, "list", []string{"env.so", "gofmt.so"}, false},
+		{"plugin after command", []string{"--dir", dir, "info", "env"}, "info", []string{"env.so"}, false},
+		{"option after command", []string{"--dir", dir, "env", "list", "--json"}, "list", []string{"env.so"}, true},
+		{"option before plugins", []string{"--json", "--dir", dir, "info", "gofmt"}, "info", []string{"gofmt.so"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := parseArgs(tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.command != tt.command {
+				t.Errorf("command = %q, want %q", cfg.command, tt.command)
+			}
+			if cfg.jsonOutput != tt.jsonOutput {
+				t.Errorf("jsonOutput = %v, want %v", cfg.jsonOutput, tt.jsonOutput)
+			}
+			var plugins []string
+			for _, path := range cfg.pluginPaths {
+				plugins = append(plugins, filepath.Base(path))
+			}
+			if !reflect.DeepEqual(plugins, tt.plugins) {
+				t.Errorf("plugins = %v, want %v", plugins, tt.plugins)
+			}
+		})
+	}
+}
+
+func TestParseArgsOutputMode(t *testing.T) {
+	cfg, err := parseArgs([]string{"execute"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.outputMode != types.OutputModeJSON {
+		t.Errorf("default outputMode = %q, want json", cfg.outputMode)
+	}
+
+	cfg, err = parseArgs([]string{"execute", "--output-mode", "exitcode"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.outputMode != types.OutputModeExitCode {
+		t.Errorf("outputMode = %q, want exitcode", cfg.outputMode)
+	}
+
+	if _, err := parseArgs([]string{"execute", "--output-mode", "text"}); err == nil {
+		t.Error("parseArgs accepted an invalid output mode")
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"claude-hooks/types"
+	"fmt"
+	"strings"
+)
+
+type Plugin struct {
+	types.UnimplementedPlugin
+	// 受保护的分支，支持glob，如 release/*
+	protectedBranches []string
+	// 允许递归删除的项目外目录
+	allowPaths []string
+	// 内置检查的动作，按检查ID索引
+	actions map[string]string
+	// 自定义规则，优先于内置检查
+	rules []*Rule
+}
+
+func New() types.IPlugin {
+	return &Plugin{
+		protectedBranches: defaultProtectedBranches,
+		allowPaths:        types.TempDirs(),
+		actions:           defaultActions(),
+	}
+}
+
+func (p *Plugin) GetMetadata() types.PluginMetadata {
+	return types.PluginMetadata{
+		Description: "阻止或确认危险的Bash命令",
+		Matchers: []types.Matcher{
+			types.OnPreToolUse(types.Tools("Bash")),
+		},
+		APIVersion:   types.APIVersion,
+		ConfigSchema: configSchema,
+	}
+}
+
+// finding 命令触发的一条规则或检查
+type finding struct {
+	action string
+	// 原因，说明命令为什么危险
+	message string
+	// 触发的简单命令
+	command string
+}
+
+func (f finding) reason() string {
+	return fmt.Sprintf("%s. Command: %s", strings.TrimSuffix(f.message, "."), f.command)
+}
+
+func (p *Plugin) PreToolUse(arg types.ToolInput) (*types.PreToolUseOutput, error) {
+	var ret types.PreToolUseOutput
+
+	input, err := arg.AsBash()
+	if err != nil {
+		return nil, err
+	}
+	commands, err := types.ParseShell(input.Command)
+	if err != nil {
+		// 无法解析的命令交给用户确认，而不是猜测其含义
+		return ret.Ask(fmt.Sprintf("Could not parse the command (%v), so it cannot be checked for destructive operations", err)), nil
+	}
+
+	g := &guard{Plugin: p, cwd: arg.Cwd, projectDir: arg.ProjectDir()}
+	f := g.check(commands)
+	if f == nil {
+		return nil, nil
+	}
+	if f.action == ActionAsk {
+		return ret.Ask(f.reason()), nil
+	}
+	return ret.Approve(false, f.reason()), nil
+}
+
+// guard 检查一次Bash调用，记录命令中的赋值和cd，使后面的rm等命令按实际的目标判断
+type guard struct {
+	*Plugin
+	cwd        string
+	projectDir string
+	// 命令中赋值的变量，如 DIR=build; rm -rf $DIR
+	vars map[string]string
+}
+
+// check 返回最严重的finding：block优先于ask，同级时取第一个
+func (g *guard) check(commands []types.ShellCommand) *finding {
+	var ask *finding
+	downloading := false
+	for _, c := range commands {
+		g.assign(c)
+		f := g.checkCommand(c, downloading)
+		// curl ... | tee install.sh | sh 中下载的内容会一直传到管道末尾
+		downloading = c.Piped() && (downloading || downloaders[c.Unwrap().Name()])
+		if f == nil {
+			continue
+		}
+		if f.action == ActionBlock {
+			return f
+		}
+		if ask == nil {
+			ask = f
+		}
+	}
+	return ask
+}
+
+// assign 记录变量赋值和export的变量，供后续命令展开路径
+func (g *guard) assign(c types.ShellCommand) {
+	assignments := c.Assignments
+	if len(c.Args) > 0 {
+		// FOO=bar cmd 只对cmd生效
+		if c.Name() != "export" {
+			return
+		}
+		assignments = c.Args[1:]
+	}
+	for _, assignment := range assignments {
+		if name, value, ok := strings.Cut(assignment, "="); ok {
+			if g.vars == nil {
+				g.vars = make(map[string]string)
+			}
+			g.vars[name] = value
+		}
+	}
+}
+
+// checkCommand 检查一条简单命令，downloading表示命令的输入来自下载
+func (g *guard) checkCommand(c types.ShellCommand, downloading bool) *finding {
+	inner := c.Unwrap()
+	if len(inner.Args) == 0 {
+		return nil
+	}
+	command := strings.Join(c.Args, " ")
+
+	for _, rule := range g.rules {
+		if !rule.Match(inner.Args) {
+			continue
+		}
+		if rule.Action == ActionAllow {
+			return nil
+		}
+		return &finding{action: rule.action(), message: rule.message(), command: command}
+	}
+
+	for _, ck := range checks {
+		action := g.actions[ck.id]
+		if action == ActionOff {
+			continue
+		}
+		if message := ck.check(g, inner, downloading); message != "" {
+			return &finding{action: action, message: message, command: command}
+		}
+	}
+	return nil
+}
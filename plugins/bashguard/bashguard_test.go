@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"claude-hooks/types"
+	"claude-hooks/types/hooktest"
+	"encoding/json"
+	"testing"
+)
+
+// setEnv 固定夹具依赖的环境：项目目录取自cwd，家目录和临时目录与夹具一致
+func setEnv(t *testing.T) {
+	t.Setenv("CLAUDE_PROJECT_DIR", "")
+	t.Setenv("HOME", "/home/user")
+	t.Setenv("TMPDIR", "/tmp")
+}
+
+func TestFixtures(t *testing.T) {
+	setEnv(t)
+	hooktest.Run(t, New, "testdata")
+}
+
+// TestSafeCommandsNoOutput 没有问题的命令不做决策，仍由Claude Code按权限设置确认，而不是被批准
+func TestSafeCommandsNoOutput(t *testing.T) {
+	setEnv(t)
+	for _, command := range []string{
+		"rm ~/notes.txt",
+		"git push origin main",
+		"go test ./... && git status",
+		"curl -s https://api.example.com/items | jq .",
+		"curl -s https://api.example.com/items | python3 -m json.tool",
+	} {
+		input, err := json.Marshal(map[string]any{
+			"hook_event_name": "PreToolUse",
+			"cwd":             "/project",
+			"tool_name":       "Bash",
+			"tool_input":      map[string]any{"command": command},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := types.RunHook([]types.IPlugin{New()}, input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var stdout, stderr bytes.Buffer
+		if code := result.Write(types.OutputModeJSON, &stdout, &stderr); code != types.ExitCodeSuccess || stdout.Len() > 0 || stderr.Len() > 0 {
+			t.Errorf("%s: code %d, stdout %q, stderr %q, want no output", command, code, stdout.String(), stderr.String())
+		}
+	}
+}
+
+// testConfig testdata/config中的夹具使用的配置
+const testConfig = `{
+  "protectedBranches": ["main", "release/*"],
+  "allowPaths": ["/var/cache/app"],
+  "checks": {"reset-hard": "block", "pipe-to-shell": "off"},
+  "rules": [
+    {"command": "terraform destroy", "action": "ask", "message": "Destroying infrastructure needs confirmation"},
+    {"regex": "^kubectl delete (ns|namespace)\\b"},
+    {"command": "git reset --hard origin/main", "action": "allow"}
+  ]
+}`
+
+func TestConfiguredFixtures(t *testing.T) {
+	setEnv(t)
+	hooktest.RunConfig(t, New, "testdata/config", json.RawMessage(testConfig))
+}
+
+func TestConfigureErrors(t *testing.T) {
+	for _, config := range []string{
+		`{"checks": {"nope": "off"}}`,
+		`{"checks": {"rm": "allow"}}`,
+		`{"rules": [{"message": "no matcher"}]}`,
+		`{"rules": [{"command": "a", "regex": "b"}]}`,
+		`{"rules": [{"regex": "("}]}`,
+		`{"rules": [{"command": "a", "action": "deny"}]}`,
+		`{"protectedBranches": ["["]}`,
+		`{"deny": []}`,
+	} {
+		if err := New().(*Plugin).Configure(json.RawMessage(config)); err == nil {
+			t.Errorf("Configure(%s) succeeded, want error", config)
+		}
+	}
+}
+
+func TestWorldWritable(t *testing.T) {
+	tests := []struct {
+		mode string
+		want bool
+	}{
+		{"777", true},
+		{"0777", true},
+		{"666", true},
+		{"755", false},
+		{"a+rwx", true},
+		{"o+w", true},
+		{"u+w,go-w", false},
+		{"+x", false},
+	}
+	for _, tt := range tests {
+		if got := worldWritable(tt.mode); got != tt.want {
+			t.Errorf("worldWritable(%q) = %v, want %v", tt.mode, got, tt.want)
+		}
+	}
+}
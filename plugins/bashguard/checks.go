@@ -0,0 +1,295 @@
+package main
+
+import (
+	"claude-hooks/types"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// check 内置检查，返回空字符串表示命令没有问题
+type check struct {
+	// 标识，用于在配置中修改动作
+	id string
+	// 默认动作
+	action string
+	// c为去除包装命令后的命令，downloading表示命令的输入来自curl、wget等下载命令
+	check func(g *guard, c types.ShellCommand, downloading bool) string
+}
+
+// checks 内置检查，按顺序执行
+var checks = []check{
+	{id: "rm", action: ActionBlock, check: checkRm},
+	{id: "force-push", action: ActionBlock, check: checkForcePush},
+	{id: "reset-hard", action: ActionAsk, check: checkResetHard},
+	{id: "chmod", action: ActionBlock, check: checkChmod},
+	{id: "dd", action: ActionBlock, check: checkDd},
+	{id: "mkfs", action: ActionBlock, check: checkMkfs},
+	{id: "pipe-to-shell", action: ActionAsk, check: checkPipeToShell},
+}
+
+// checkRm 递归删除只允许在项目内或allowPaths中进行
+func checkRm(g *guard, c types.ShellCommand, _ bool) string {
+	if c.Name() != "rm" {
+		return ""
+	}
+	recursive := false
+	endOfOptions := false
+	var paths []string
+	for _, arg := range c.Args[1:] {
+		switch {
+		case endOfOptions || arg == "-" || !strings.HasPrefix(arg, "-"):
+			paths = append(paths, arg)
+		case arg == "--":
+			endOfOptions = true
+		case strings.HasPrefix(arg, "--"):
+			recursive = recursive || arg == "--recursive"
+		default:
+			recursive = recursive || strings.ContainsAny(arg, "rR")
+		}
+	}
+	if !recursive {
+		return ""
+	}
+
+	for _, p := range paths {
+		abs, ok := g.resolve(p)
+		if !ok {
+			return fmt.Sprintf("Recursive rm of %s, which cannot be resolved before running; use a literal path inside the project", p)
+		}
+		if g.projectDir != "" && abs == filepath.Clean(g.projectDir) {
+			return fmt.Sprintf("Recursive rm of %s would delete the whole project", p)
+		}
+		if g.removable(abs) {
+			continue
+		}
+		if abs != p {
+			p = fmt.Sprintf("%s (%s)", p, abs)
+		}
+		return fmt.Sprintf("Recursive rm of %s is outside the project; only paths under %s can be deleted recursively", p, strings.Join(g.removableRoots(), ", "))
+	}
+	return ""
+}
+
+// removableRoots 允许递归删除的目录
+func (g *guard) removableRoots() []string {
+	var roots []string
+	if g.projectDir != "" {
+		roots = append(roots, g.projectDir)
+	}
+	return append(roots, g.allowPaths...)
+}
+
+// removable 判断路径是否在允许递归删除的目录之内（不包括目录本身）
+func (g *guard) removable(abs string) bool {
+	for _, root := range g.removableRoots() {
+		root, ok := g.resolve(root)
+		if !ok {
+			continue
+		}
+		if rel, err := filepath.Rel(root, abs); err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, "../") {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve 展开~和环境变量，返回基于工作目录清理后的绝对路径
+// 路径包含命令替换、未定义的变量等运行前无法确定的内容时返回false
+func (g *guard) resolve(p string) (string, bool) {
+	p, ok := types.ExpandVariables(p, g.cwd, g.vars)
+	if !ok {
+		return "", false
+	}
+	return types.ResolvePath(p, g.cwd)
+}
+
+func checkForcePush(g *guard, c types.ShellCommand, _ bool) string {
+	git, ok := types.ParseGitCommand(c)
+	if !ok || git.Subcommand != "push" {
+		return ""
+	}
+	push := parsePush(git.Args)
+	if push.all && push.force {
+		return fmt.Sprintf("Force-pushing all branches includes the protected branches (%s); push a single feature branch instead", strings.Join(g.protectedBranches, ", "))
+	}
+
+	for _, ref := range push.refs {
+		if !ref.force && !ref.delete {
+			continue
+		}
+		branch := ref.branch
+		if branch == "" {
+			branch = types.CurrentBranch(git.WorkDir(g.cwd))
+		}
+		if branch == "" {
+			return fmt.Sprintf("Force push to the current branch, which could not be determined, may rewrite a protected branch (%s)", strings.Join(g.protectedBranches, ", "))
+		}
+		if !g.protected(branch) {
+			continue
+		}
+		if ref.delete {
+			return fmt.Sprintf("Deleting the protected branch %s is not allowed", branch)
+		}
+		return fmt.Sprintf("Force-pushing to the protected branch %s rewrites shared history; push to a feature branch and open a pull request instead", branch)
+	}
+	return ""
+}
+
+// protected 判断分支是否受保护
+func (g *guard) protected(branch string) bool {
+	for _, pattern := range g.protectedBranches {
+		if matched, _ := filepath.Match(pattern, branch); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func checkResetHard(_ *guard, c types.ShellCommand, _ bool) string {
+	git, ok := types.ParseGitCommand(c)
+	if !ok || git.Subcommand != "reset" {
+		return ""
+	}
+	for _, arg := range git.Args {
+		if arg == "--hard" {
+			return "git reset --hard discards all uncommitted changes; commit or stash them first (git stash), or reset only the files you mean to"
+		}
+	}
+	return ""
+}
+
+// checkChmod 递归地让文件所有人可写
+func checkChmod(_ *guard, c types.ShellCommand, _ bool) string {
+	if c.Name() != "chmod" {
+		return ""
+	}
+	recursive := false
+	mode := ""
+	for _, arg := range c.Args[1:] {
+		switch {
+		case arg == "--recursive":
+			recursive = true
+		case strings.HasPrefix(arg, "--"):
+		case strings.HasPrefix(arg, "-") && strings.Trim(arg[1:], "rwxXst") != "":
+			// -R、-Rv等选项；-w、-x等是符号模式
+			recursive = recursive || strings.Contains(arg, "R")
+		case mode == "":
+			mode = arg
+		}
+	}
+	if !recursive || !worldWritable(mode) {
+		return ""
+	}
+	return fmt.Sprintf("chmod -R %s makes every file world-writable; grant only the permissions needed, to the files that need them", mode)
+}
+
+// worldWritable 判断chmod的模式是否给其他用户写权限，如 777、o+w、a=rwx
+func worldWritable(mode string) bool {
+	if n, err := strconv.ParseUint(mode, 8, 32); err == nil {
+		return n&0o002 != 0
+	}
+	for _, clause := range strings.Split(mode, ",") {
+		i := strings.IndexAny(clause, "+=")
+		if i < 0 {
+			continue
+		}
+		who := clause[:i]
+		if (who == "" || strings.ContainsAny(who, "ao")) && strings.Contains(clause[i:], "w") {
+			return true
+		}
+	}
+	return false
+}
+
+// safeDevices dd可以写入的设备
+var safeDevices = map[string]bool{"/dev/null": true, "/dev/zero": true, "/dev/stdout": true, "/dev/stderr": true}
+
+func checkDd(_ *guard, c types.ShellCommand, _ bool) string {
+	if c.Name() != "dd" {
+		return ""
+	}
+	for _, arg := range c.Args[1:] {
+		target, ok := strings.CutPrefix(arg, "of=")
+		if ok && strings.HasPrefix(target, "/dev/") && !safeDevices[target] && !strings.HasPrefix(target, "/dev/fd/") {
+			return fmt.Sprintf("dd writes directly to the device %s and can destroy the data on it", target)
+		}
+	}
+	return ""
+}
+
+func checkMkfs(_ *guard, c types.ShellCommand, _ bool) string {
+	name := c.Name()
+	if name == "mkfs" || strings.HasPrefix(name, "mkfs.") || name == "mke2fs" || name == "mkswap" || name == "wipefs" {
+		return fmt.Sprintf("%s erases the data on the target device", name)
+	}
+	return ""
+}
+
+// downloaders 下载命令
+var downloaders = map[string]bool{"curl": true, "wget": true, "fetch": true}
+
+// shells 从标准输入读取脚本的shell，-s表示从标准输入读取
+var shells = map[string]bool{"sh": true, "bash": true, "zsh": true, "dash": true, "ksh": true, "fish": true}
+
+// interpreterCodeOptions 解释器中表示代码来自参数而不是标准输入的选项
+// 这些代码仍然可以读取并执行标准输入，如 python -c 'exec(sys.stdin.read())'
+var interpreterCodeOptions = map[string]bool{"-c": true, "-e": true, "-E": true, "-r": true, "--eval": true}
+
+// downloadSubstitution 参数中执行下载内容的命令替换或进程替换，如 $(curl ...)、<(wget ...)
+var downloadSubstitution = regexp.MustCompile("(?:\\$\\(|<\\(|`)\\s*(?:curl|wget|fetch)\\b")
+
+// checkPipeToShell 未经检查就执行下载的脚本
+func checkPipeToShell(_ *guard, c types.ShellCommand, downloading bool) string {
+	name := c.Name()
+	if !isInterpreter(name) && name != "source" && name != "." {
+		if downloadSubstitution.MatchString(c.Args[0]) {
+			return "Running the output of a download executes code that has not been reviewed; download it to a file and inspect it first"
+		}
+		return ""
+	}
+
+	for _, arg := range c.Args[1:] {
+		if downloadSubstitution.MatchString(arg) {
+			return fmt.Sprintf("Running a downloaded script with %s executes code that has not been reviewed; download it to a file and inspect it first", name)
+		}
+	}
+	if downloading && readsProgram(c) {
+		return fmt.Sprintf("Piping a download into %s executes code that has not been reviewed; download it to a file and inspect it first", name)
+	}
+	return ""
+}
+
+func isInterpreter(name string) bool {
+	if shells[name] {
+		return true
+	}
+	for _, prefix := range []string{"python", "perl", "ruby", "node", "php"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// readsProgram 判断解释器是否可能执行标准输入中的代码：从标准输入读取脚本，
+// 或者执行-c等参数中的代码，这些代码能读取标准输入；-m运行的模块和脚本文件不算
+func readsProgram(c types.ShellCommand) bool {
+	for _, arg := range c.Args[1:] {
+		switch {
+		case arg == "-" || (arg == "-s" && shells[c.Name()]):
+			return true
+		case interpreterCodeOptions[arg]:
+			return true
+		case arg == "--" || arg == "-m":
+			return false
+		case strings.HasPrefix(arg, "-"):
+		default:
+			// 脚本文件
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package main
+
+import "strings"
+
+// pushRef git push更新的一个分支
+type pushRef struct {
+	// 目标分支，为空表示当前分支
+	branch string
+	force  bool
+	delete bool
+}
+
+// pushCommand 解析后的git push参数
+type pushCommand struct {
+	refs []pushRef
+	// --all、--mirror：推送所有分支
+	all   bool
+	force bool
+}
+
+// pushValueOptions 需要参数值的git push选项
+var pushValueOptions = map[string]bool{"--repo": true, "-o": true, "--push-option": true, "--receive-pack": true, "--exec": true}
+
+// parsePush 解析git push的参数，没有指定refspec时推送当前分支
+func parsePush(args []string) pushCommand {
+	var (
+		push         pushCommand
+		deleting     bool
+		positional   []string
+		endOfOptions bool
+	)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		option, _, hasValue := strings.Cut(arg, "=")
+		switch {
+		case endOfOptions || !strings.HasPrefix(arg, "-"):
+			positional = append(positional, arg)
+		case arg == "--":
+			endOfOptions = true
+		case option == "--force" || option == "--force-with-lease":
+			push.force = true
+		case arg == "--delete":
+			deleting = true
+		case arg == "--all" || arg == "--mirror" || arg == "--branches":
+			push.all = true
+		case pushValueOptions[option]:
+			if !hasValue {
+				i++
+			}
+		case !strings.HasPrefix(arg, "--"):
+			// -f、-d、-fu等短选项
+			push.force = push.force || strings.Contains(arg, "f")
+			deleting = deleting || strings.Contains(arg, "d")
+		}
+	}
+
+	// 第一个位置参数是远程仓库
+	var refspecs []string
+	if len(positional) > 1 {
+		refspecs = positional[1:]
+	}
+	if len(refspecs) == 0 && !push.all {
+		push.refs = []pushRef{{force: push.force, delete: deleting}}
+		return push
+	}
+
+	for _, refspec := range refspecs {
+		ref := pushRef{force: push.force, delete: deleting}
+		if rest, ok := strings.CutPrefix(refspec, "+"); ok {
+			ref.force = true
+			refspec = rest
+		}
+		src, dst, found := strings.Cut(refspec, ":")
+		if !found {
+			dst = src
+		} else if src == "" {
+			// :branch 删除远程分支
+			ref.delete = true
+		}
+		if dst != "HEAD" {
+			ref.branch = strings.TrimPrefix(dst, "refs/heads/")
+		}
+		push.refs = append(push.refs, ref)
+	}
+	return push
+}
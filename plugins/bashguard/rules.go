@@ -0,0 +1,203 @@
+package main
+
+import (
+	"claude-hooks/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Config bashguard插件的配置，从 .claude/plugins/bashguard.json 读取
+//
+//	{
+//	  "protectedBranches": ["main", "release/*"],
+//	  "allowPaths": ["/tmp", "~/.cache"],
+//	  "checks": {"reset-hard": "block", "dd": "off"},
+//	  "rules": [
+//	    {"command": "terraform destroy", "action": "ask"},
+//	    {"command": "git reset --hard HEAD", "action": "allow"}
+//	  ]
+//	}
+type Config struct {
+	// 受保护的分支，默认为main和master
+	ProtectedBranches []string `json:"protectedBranches,omitempty"`
+	// 允许递归删除的项目外目录，默认为临时目录
+	AllowPaths []string `json:"allowPaths,omitempty"`
+	// 覆盖内置检查的动作：block、ask或off
+	Checks map[string]string `json:"checks,omitempty"`
+	// 自定义规则，按顺序匹配，优先于内置检查
+	Rules []Rule `json:"rules,omitempty"`
+}
+
+// Rule 自定义命令规则，Command和Regex二选一
+type Rule struct {
+	// 命令开头的单词，如 "terraform destroy"，包装命令（sudo、env等）会被跳过
+	Command string `json:"command,omitempty"`
+	// 命令的正则，在以空格连接的单词中搜索
+	Regex string `json:"regex,omitempty"`
+	// 阻止时的原因，为空时使用默认原因
+	Message string `json:"message,omitempty"`
+	// block（默认）、ask或allow；allow的命令跳过内置检查
+	Action string `json:"action,omitempty"`
+
+	words []string
+	re    *regexp.Regexp
+}
+
+const (
+	ActionBlock = "block"
+	ActionAsk   = "ask"
+	ActionAllow = "allow"
+	// ActionOff 关闭内置检查，只用于checks
+	ActionOff = "off"
+)
+
+// defaultMessage bashguard.json中的规则没有写message时的原因，提示Claude命令是被项目规则拦下的
+const defaultMessage = "This command is not allowed by the project's bashguard rules"
+
+// defaultProtectedBranches 默认受保护的分支
+var defaultProtectedBranches = []string{"main", "master"}
+
+// defaultActions 内置检查的默认动作
+func defaultActions() map[string]string {
+	actions := make(map[string]string, len(checks))
+	for _, ck := range checks {
+		actions[ck.id] = ck.action
+	}
+	return actions
+}
+
+// configSchema bashguard.json的格式，checks的键为内置检查的ID
+var configSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "protectedBranches": {"type": "array", "items": {"type": "string"}, "description": "Branches that must not be force-pushed or deleted (default main, master)"},
+    "allowPaths": {"type": "array", "items": {"type": "string"}, "description": "Directories outside the project where recursive rm is allowed"},
+    "checks": {
+      "type": "object",
+      "propertyNames": {"enum": ["rm", "force-push", "reset-hard", "chmod", "dd", "mkfs", "pipe-to-shell"]},
+      "additionalProperties": {"enum": ["block", "ask", "off"]}
+    },
+    "rules": {"type": "array", "items": {"$ref": "#/$defs/rule"}}
+  },
+  "additionalProperties": false,
+  "$defs": {
+    "rule": {
+      "type": "object",
+      "properties": {
+        "command": {"type": "string"},
+        "regex": {"type": "string"},
+        "message": {"type": "string"},
+        "action": {"enum": ["block", "ask", "allow"]}
+      },
+      "additionalProperties": false
+    }
+  }
+}`)
+
+// compile 检查command和regex是否恰好设置了一个，把command拆成单词用于按前缀匹配命令
+func (r *Rule) compile() error {
+	switch {
+	case r.Command != "" && r.Regex != "":
+		return errors.New("command and regex are mutually exclusive")
+	case r.Command != "":
+		r.words = strings.Fields(r.Command)
+	case r.Regex != "":
+		re, err := regexp.Compile(r.Regex)
+		if err != nil {
+			return fmt.Errorf("invalid regex %q: %v", r.Regex, err)
+		}
+		r.re = re
+	default:
+		return errors.New("command or regex is required")
+	}
+
+	switch r.Action {
+	case "", ActionBlock, ActionAsk, ActionAllow:
+	default:
+		return fmt.Errorf("invalid action %q (expected block, ask or allow)", r.Action)
+	}
+	return nil
+}
+
+// Match 判断命令（已去除包装命令）是否匹配规则
+func (r *Rule) Match(args []string) bool {
+	if r.re != nil {
+		return r.re.MatchString(strings.Join(args, " "))
+	}
+	if len(args) < len(r.words) {
+		return false
+	}
+	for i, word := range r.words {
+		arg := args[i]
+		if i == 0 {
+			arg = path.Base(arg)
+		}
+		if arg != word {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Rule) action() string {
+	if r.Action == "" {
+		return ActionBlock
+	}
+	return r.Action
+}
+
+func (r *Rule) message() string {
+	if r.Message == "" {
+		return defaultMessage
+	}
+	return r.Message
+}
+
+// Configure 校验各项检查的动作并编译自定义规则，未在checks中出现的检查保持默认动作
+func (p *Plugin) Configure(data json.RawMessage) error {
+	var config Config
+	if err := types.DecodeConfig(data, &config); err != nil {
+		return err
+	}
+
+	for _, branch := range config.ProtectedBranches {
+		if _, err := path.Match(branch, ""); err != nil {
+			return fmt.Errorf("protectedBranches: invalid glob %q: %v", branch, err)
+		}
+	}
+
+	actions := defaultActions()
+	for id, action := range config.Checks {
+		if _, ok := actions[id]; !ok {
+			return fmt.Errorf("checks: unknown check %q", id)
+		}
+		switch action {
+		case ActionBlock, ActionAsk, ActionOff:
+		default:
+			return fmt.Errorf("checks.%s: invalid action %q (expected block, ask or off)", id, action)
+		}
+		actions[id] = action
+	}
+
+	var rules []*Rule
+	for i := range config.Rules {
+		rule := &config.Rules[i]
+		if err := rule.compile(); err != nil {
+			return fmt.Errorf("rules[%d]: %v", i, err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if config.ProtectedBranches != nil {
+		p.protectedBranches = config.ProtectedBranches
+	}
+	if config.AllowPaths != nil {
+		p.allowPaths = config.AllowPaths
+	}
+	p.actions, p.rules = actions, rules
+	return nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"claude-hooks/types"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Config boundary插件的配置，从 .claude/plugins/boundary.json 读取
+//
+//	{
+//	  "writePaths": ["/tmp", "~/.cache/myapp"],
+//	  "restrictReads": true,
+//	  "readPaths": ["~/go/pkg/mod", "/usr/local/go"]
+//	}
+type Config struct {
+	// 项目外允许写入的目录，默认为临时目录
+	WritePaths []string `json:"writePaths,omitempty"`
+	// 是否限制读取项目外的文件，默认为false
+	RestrictReads bool `json:"restrictReads,omitempty"`
+	// restrictReads为true时项目外允许读取的目录，默认为Go模块缓存；writePaths也可以读取
+	ReadPaths []string `json:"readPaths,omitempty"`
+}
+
+// configSchema boundary.json的格式，目录可以以~开头
+var configSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "writePaths": {"type": "array", "items": {"type": "string"}, "description": "Directories outside the project that may be written (default: the temp dir)"},
+    "restrictReads": {"type": "boolean", "description": "Also block reads outside the project and readPaths"},
+    "readPaths": {"type": "array", "items": {"type": "string"}, "description": "Directories outside the project that may be read when restrictReads is set (default: the Go module cache)"}
+  },
+  "additionalProperties": false
+}`)
+
+// writeTools 写文件的工具
+var writeTools = map[string]bool{"Write": true, "Edit": true, "MultiEdit": true, "NotebookEdit": true}
+
+type Plugin struct {
+	types.UnimplementedPlugin
+	writePaths    []string
+	restrictReads bool
+	readPaths     []string
+}
+
+func New() types.IPlugin {
+	return &Plugin{writePaths: types.TempDirs(), readPaths: defaultReadPaths()}
+}
+
+func (p *Plugin) GetMetadata() types.PluginMetadata {
+	return types.PluginMetadata{
+		Description: "阻止在项目目录外写入文件",
+		Matchers: []types.Matcher{
+			types.OnPreToolUse(types.Tools("Write", "Edit", "MultiEdit", "NotebookEdit", "Read", "Grep", "Glob", "LS", "Bash")),
+		},
+		APIVersion:   types.APIVersion,
+		ConfigSchema: configSchema,
+	}
+}
+
+// Configure 设置项目外可以写入和读取的目录，未设置的列表保持默认值
+func (p *Plugin) Configure(data json.RawMessage) error {
+	var config Config
+	if err := types.DecodeConfig(data, &config); err != nil {
+		return err
+	}
+	for i, dir := range config.WritePaths {
+		if dir == "" {
+			return fmt.Errorf("writePaths[%d]: empty path", i)
+		}
+	}
+	for i, dir := range config.ReadPaths {
+		if dir == "" {
+			return fmt.Errorf("readPaths[%d]: empty path", i)
+		}
+	}
+
+	if config.WritePaths != nil {
+		p.writePaths = config.WritePaths
+	}
+	if config.ReadPaths != nil {
+		p.readPaths = config.ReadPaths
+	}
+	p.restrictReads = config.RestrictReads
+	return nil
+}
+
+// violation 对项目外路径的一次访问
+type violation struct {
+	// 被访问的对象，如 "File: /etc/hosts"
+	subject string
+	write   bool
+	// 路径在运行前无法确定，如包含未定义的变量
+	unresolved bool
+}
+
+func (p *Plugin) PreToolUse(arg types.ToolInput) (*types.PreToolUseOutput, error) {
+	var ret types.PreToolUseOutput
+
+	projectDir := arg.ProjectDir()
+	if projectDir == "" {
+		return nil, nil
+	}
+	b := &boundary{Plugin: p, cwd: arg.Cwd, root: projectDir}
+
+	var v *violation
+	if arg.ToolName == "Bash" {
+		input, err := arg.AsBash()
+		if err != nil {
+			return nil, err
+		}
+		v = b.checkCommand(input.Command)
+	} else {
+		v = b.checkTool(arg.ToolName, arg.GetTargetPath())
+	}
+	if v == nil {
+		return nil, nil
+	}
+
+	if v.unresolved {
+		return ret.Ask(fmt.Sprintf("The target cannot be resolved before running, so it may be outside the project (%s). %s", projectDir, v.subject)), nil
+	}
+	action := "Reading"
+	if v.write {
+		action = "Writing"
+	}
+	return ret.Approve(false, fmt.Sprintf("%s outside the project (%s) is not allowed. %s", action, projectDir, v.subject)), nil
+}
+
+// checkTool 检查文件工具的目标路径
+func (b *boundary) checkTool(toolName, target string) *violation {
+	write := writeTools[toolName]
+	if target == "" || (!write && !b.restrictReads) {
+		return nil
+	}
+	subject := "File: "
+	if !write && toolName != "Read" {
+		subject = "Path: "
+	}
+	if note, ok := b.allowed(target, write); !ok {
+		return &violation{subject: subject + target + note, write: write}
+	}
+	return nil
+}
+
+// checkCommand 检查Bash命令的重定向目标和cd目标
+// cd会改变后续命令中相对路径的基准，如 cd /etc && echo x > hosts 写入的是/etc/hosts
+func (b *boundary) checkCommand(command string) *violation {
+	commands, err := types.ParseShell(command)
+	if err != nil {
+		// 无法解析时不能确定写入的目标，交给用户确认，与bashguard一致
+		return &violation{subject: fmt.Sprintf("Could not parse the command (%v). Command: %s", err, command), unresolved: true}
+	}
+
+	for _, c := range commands {
+		for _, r := range c.Redirects {
+			if !r.IsFile() {
+				continue
+			}
+			write := strings.Contains(r.Op, ">")
+			if !write && !b.restrictReads {
+				continue
+			}
+			target, ok := b.expand(r.Target)
+			if !ok {
+				return &violation{subject: "Command redirects to: " + r.Target, write: write, unresolved: true}
+			}
+			if note, ok := b.allowed(target, write); !ok {
+				if note == "" && target != r.Target {
+					note = " (resolves to " + target + ")"
+				}
+				return &violation{subject: "Command redirects to: " + r.Target + note, write: write, unresolved: note == unresolvedNote}
+			}
+		}
+
+		target, ok := c.Unwrap().DirTarget()
+		if !ok {
+			continue
+		}
+		if target == "-" {
+			// cd - 回到的目录未知
+			b.cwd = ""
+			continue
+		}
+		expanded, ok := b.expand(target)
+		if !ok {
+			b.cwd = ""
+			continue
+		}
+		dir, ok := b.resolve(expanded)
+		if !ok {
+			b.cwd = ""
+			continue
+		}
+		if b.restrictReads {
+			if note, ok := b.allowed(expanded, false); !ok {
+				return &violation{subject: "Command changes directory to: " + target + note}
+			}
+		}
+		b.cwd = dir
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"claude-hooks/types"
+	"claude-hooks/types/hooktest"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setEnv 固定夹具依赖的环境：项目目录取自cwd，家目录、临时目录和模块缓存与夹具一致
+func setEnv(t *testing.T) {
+	t.Setenv("CLAUDE_PROJECT_DIR", "")
+	t.Setenv("HOME", "/home/user")
+	t.Setenv("TMPDIR", "/tmp")
+	t.Setenv("GOMODCACHE", "")
+	t.Setenv("GOPATH", "")
+}
+
+func TestFixtures(t *testing.T) {
+	setEnv(t)
+	hooktest.Run(t, New, "testdata")
+}
+
+// testConfig testdata/config中的夹具使用的配置
+const testConfig = `{
+  "writePaths": ["/var/cache/app"],
+  "restrictReads": true,
+  "readPaths": ["~/go/pkg/mod", "/usr/local/go"]
+}`
+
+func TestConfiguredFixtures(t *testing.T) {
+	setEnv(t)
+	hooktest.RunConfig(t, New, "testdata/config", json.RawMessage(testConfig))
+}
+
+func TestConfigureErrors(t *testing.T) {
+	for _, config := range []string{
+		`{"writePaths": [""]}`,
+		`{"readPaths": [""]}`,
+		`{"restrictReads": "yes"}`,
+		`{"allow": []}`,
+	} {
+		if err := New().(*Plugin).Configure(json.RawMessage(config)); err == nil {
+			t.Errorf("Configure(%s) succeeded, want error", config)
+		}
+	}
+}
+
+func TestDefaultReadPaths(t *testing.T) {
+	setEnv(t)
+	if got := defaultReadPaths(); len(got) != 1 || got[0] != "~/go/pkg/mod" {
+		t.Errorf("defaultReadPaths() = %v", got)
+	}
+	t.Setenv("GOPATH", "/gopath"+string(filepath.ListSeparator)+"/other")
+	if got := defaultReadPaths(); got[0] != "/gopath/pkg/mod" {
+		t.Errorf("defaultReadPaths() with GOPATH = %v", got)
+	}
+	t.Setenv("GOMODCACHE", "/cache/mod")
+	if got := defaultReadPaths(); got[0] != "/cache/mod" {
+		t.Errorf("defaultReadPaths() with GOMODCACHE = %v", got)
+	}
+}
+
+func TestSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	project := filepath.Join(dir, "project")
+	outside := filepath.Join(dir, "outside")
+	for _, d := range []string{project, outside} {
+		if err := os.Mkdir(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// project/out -> ../outside，通过项目内的链接写到项目外
+	if err := os.Symlink(outside, filepath.Join(project, "out")); err != nil {
+		t.Fatal(err)
+	}
+	// link -> project，通过项目外的链接写到项目内
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(project, link); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CLAUDE_PROJECT_DIR", project)
+
+	tests := []struct {
+		name  string
+		tool  string
+		input map[string]any
+		// 期望阻止原因中包含的内容，为空表示允许
+		want string
+	}{
+		{"symlink out of project", "Write", map[string]any{"file_path": "out/evil.sh"}, "File: out/evil.sh (resolves to " + filepath.Join(outside, "evil.sh") + ")"},
+		{"symlink into project", "Write", map[string]any{"file_path": filepath.Join(link, "new/file.go")}, ""},
+		{"redirect through symlink", "Bash", map[string]any{"command": "echo x > out/y"}, "Command redirects to: out/y (resolves to " + filepath.Join(outside, "y") + ")"},
+		{"cd through symlink", "Bash", map[string]any{"command": "cd out && echo x > y"}, "Command redirects to: y (resolves to " + filepath.Join(outside, "y") + ")"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := New().(*Plugin)
+			// t.TempDir()在临时目录下，默认允许写入
+			if err := plugin.Configure(json.RawMessage(`{"writePaths": ["/nonexistent"]}`)); err != nil {
+				t.Fatal(err)
+			}
+			arg := types.ToolInput{ToolName: tt.tool, ToolInput: tt.input}
+			arg.Cwd = project
+			output, err := plugin.PreToolUse(arg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.want == "" {
+				if output != nil {
+					t.Errorf("blocked: %s", *output.Reason)
+				}
+				return
+			}
+			if output == nil || output.Reason == nil {
+				t.Fatalf("not blocked, want reason containing %q", tt.want)
+			}
+			if !strings.Contains(*output.Reason, tt.want) {
+				t.Errorf("reason = %q, want it to contain %q", *output.Reason, tt.want)
+			}
+		})
+	}
+}
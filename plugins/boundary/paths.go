@@ -0,0 +1,77 @@
+package main
+
+import (
+	"claude-hooks/types"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// boundary 一次工具调用的检查状态，Bash命令中的cd会移动解析相对路径的基准
+type boundary struct {
+	*Plugin
+	// 当前工作目录，Bash命令中的cd会改变它，为空表示未知
+	cwd string
+	// 项目根目录
+	root string
+}
+
+// unresolvedNote 路径在运行前无法确定时的附注
+const unresolvedNote = " (cannot be resolved)"
+
+// devices 总是允许访问的设备文件
+var devices = map[string]bool{"/dev/null": true, "/dev/stdin": true, "/dev/stdout": true, "/dev/stderr": true, "/dev/tty": true}
+
+// defaultReadPaths 默认允许读取的项目外目录：Go模块缓存
+func defaultReadPaths() []string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return []string{dir}
+	}
+	if gopath := os.Getenv("GOPATH"); gopath != "" {
+		return []string{filepath.Join(filepath.SplitList(gopath)[0], "pkg", "mod")}
+	}
+	return []string{"~/go/pkg/mod"}
+}
+
+// allowed 判断路径是否可以访问，不可以时返回说明路径如何解析的附注
+func (b *boundary) allowed(p string, write bool) (string, bool) {
+	abs, ok := b.resolve(p)
+	if !ok {
+		return unresolvedNote, false
+	}
+	if devices[abs] || strings.HasPrefix(abs, "/dev/fd/") {
+		return "", true
+	}
+
+	roots := append([]string{b.root}, b.writePaths...)
+	if !write {
+		roots = append(roots, b.readPaths...)
+	}
+	real := types.RealPath(abs)
+	for _, root := range roots {
+		rootAbs, ok := b.resolve(root)
+		if ok && within(types.RealPath(rootAbs), real) {
+			return "", true
+		}
+	}
+	if real != p {
+		return " (resolves to " + real + ")", false
+	}
+	return "", false
+}
+
+// within 判断path是否为dir或dir之下的路径
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+}
+
+// resolve 展开~，返回基于当前工作目录的绝对路径
+func (b *boundary) resolve(p string) (string, bool) {
+	return types.ResolvePath(p, b.cwd)
+}
+
+// expand 展开Bash命令中的环境变量，$PWD为当前工作目录
+func (b *boundary) expand(word string) (string, bool) {
+	return types.ExpandVariables(word, b.cwd, nil)
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"claude-hooks/types"
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Config branchguard插件的配置，从 .claude/plugins/branchguard.json 读取
+//
+//	{
+//	  "protectedBranches": ["main", "release/*"],
+//	  "protectedPaths": [".github/workflows/**", "go.mod"],
+//	  "allowFiles": ["docs/**"]
+//	}
+type Config struct {
+	// 受保护的分支，支持glob，默认为main和master
+	ProtectedBranches []string `json:"protectedBranches,omitempty"`
+	// 受保护的路径glob，相对仓库根目录；提交中包含这些路径的修改时阻止git commit
+	ProtectedPaths []string `json:"protectedPaths,omitempty"`
+	// 在受保护的分支上也允许编辑的文件glob，相对仓库根目录
+	AllowFiles []string `json:"allowFiles,omitempty"`
+}
+
+// configSchema branchguard.json的格式，分支和路径都是glob
+var configSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "protectedBranches": {"type": "array", "items": {"type": "string"}, "description": "Branches that must not be edited or committed to (default main, master)"},
+    "protectedPaths": {"type": "array", "items": {"type": "string"}, "description": "Globs of paths whose changes must not be committed"},
+    "allowFiles": {"type": "array", "items": {"type": "string"}, "description": "Globs of files that may be edited on protected branches"}
+  },
+  "additionalProperties": false
+}`)
+
+// defaultProtectedBranches 默认受保护的分支
+var defaultProtectedBranches = []string{"main", "master"}
+
+// editTools 编辑文件的工具
+var editTools = map[string]bool{"Write": true, "Edit": true, "MultiEdit": true, "NotebookEdit": true}
+
+// commitCommands 在当前分支上创建提交的git子命令
+var commitCommands = map[string]bool{"commit": true, "cherry-pick": true, "revert": true, "am": true}
+
+type Plugin struct {
+	types.UnimplementedPlugin
+	protectedBranches []string
+	protectedPaths    []string
+	allowFiles        []string
+}
+
+func New() types.IPlugin {
+	return &Plugin{protectedBranches: defaultProtectedBranches}
+}
+
+func (p *Plugin) GetMetadata() types.PluginMetadata {
+	return types.PluginMetadata{
+		Description: "阻止在受保护的分支上编辑和提交",
+		Matchers: []types.Matcher{
+			types.OnPreToolUse(types.Tools("Write", "Edit", "MultiEdit", "NotebookEdit", "Bash")),
+		},
+		APIVersion:   types.APIVersion,
+		ConfigSchema: configSchema,
+	}
+}
+
+// Configure 校验分支和路径的glob，未设置的列表保持默认值
+func (p *Plugin) Configure(data json.RawMessage) error {
+	var config Config
+	if err := types.DecodeConfig(data, &config); err != nil {
+		return err
+	}
+	for _, list := range []struct {
+		name  string
+		globs []string
+	}{
+		{"protectedBranches", config.ProtectedBranches},
+		{"protectedPaths", config.ProtectedPaths},
+		{"allowFiles", config.AllowFiles},
+	} {
+		for i, glob := range list.globs {
+			if _, err := path.Match(path.Base(glob), ""); err != nil || glob == "" {
+				return fmt.Errorf("%s[%d]: invalid glob %q", list.name, i, glob)
+			}
+		}
+	}
+
+	if config.ProtectedBranches != nil {
+		p.protectedBranches = config.ProtectedBranches
+	}
+	p.protectedPaths, p.allowFiles = config.ProtectedPaths, config.AllowFiles
+	return nil
+}
+
+func (p *Plugin) PreToolUse(arg types.ToolInput) (*types.PreToolUseOutput, error) {
+	var ret types.PreToolUseOutput
+
+	if editTools[arg.ToolName] {
+		if reason := p.checkEdit(arg.GetTargetPath(), arg.Cwd); reason != "" {
+			return ret.Approve(false, reason), nil
+		}
+		return nil, nil
+	}
+	if arg.ToolName != "Bash" {
+		return nil, nil
+	}
+	input, err := arg.AsBash()
+	if err != nil {
+		return nil, err
+	}
+	v := p.checkCommand(input.Command, arg.Cwd)
+	if v == nil {
+		return nil, nil
+	}
+	if v.unresolved {
+		return ret.Ask(v.reason), nil
+	}
+	return ret.Approve(false, v.reason), nil
+}
+
+// violation 命令中违反分支保护的提交
+type violation struct {
+	reason string
+	// 无法确定命令是否提交到受保护的分支，交给用户确认
+	unresolved bool
+}
+
+// protected 判断分支是否受保护
+func (p *Plugin) protected(branch string) bool {
+	for _, pattern := range p.protectedBranches {
+		if matched, _ := path.Match(pattern, branch); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// featureBranchHint 提示先创建功能分支
+const featureBranchHint = "create a feature branch first with `git switch -c <feature-name>`"
+
+// checkEdit 在受保护的分支上编辑仓库中的文件时返回阻止原因
+func (p *Plugin) checkEdit(filePath, cwd string) string {
+	if filePath == "" {
+		return ""
+	}
+	if !filepath.IsAbs(filePath) && cwd != "" {
+		filePath = filepath.Join(cwd, filePath)
+	}
+	repo, ok := types.FindGitRepo(filepath.Dir(filePath))
+	if !ok {
+		return ""
+	}
+	branch := repo.Branch()
+	if !p.protected(branch) {
+		return ""
+	}
+	if rel, err := filepath.Rel(repo.Root, filePath); err == nil {
+		for _, glob := range p.allowFiles {
+			if types.MatchGlob(glob, rel) {
+				return ""
+			}
+		}
+	}
+	return fmt.Sprintf("The branch %s is protected; %s, then make the change. File: %s", branch, featureBranchHint, filePath)
+}
+
+// checkCommand 检查Bash命令中的git提交：不能提交到受保护的分支，提交中不能包含受保护路径的修改
+// 同一条命令中先前的cd、git switch -c、git checkout -b 和 git add 会被考虑在内
+func (p *Plugin) checkCommand(command, cwd string) *violation {
+	commands, err := types.ParseShell(command)
+	if err != nil {
+		// 无法解析时不能确定提交到哪个分支，含有git提交的命令交给用户确认，与bashguard一致
+		if gitPattern.MatchString(command) && commitPattern.MatchString(command) {
+			return &violation{reason: fmt.Sprintf("Could not parse the command (%v), so it cannot be checked for commits to protected branches. Command: %s", err, command), unresolved: true}
+		}
+		return nil
+	}
+
+	// 命令中切换到的分支，switched为false表示仍在仓库的当前分支上
+	var (
+		branch   string
+		switched bool
+		adds     []gitAdd
+	)
+	for _, c := range commands {
+		inner := c.Unwrap()
+		if target, ok := inner.DirTarget(); ok {
+			// cd改变后续git命令所在的仓库，与boundary一致；无法确定时cwd为空
+			cwd = changeDir(target, cwd)
+			continue
+		}
+		git, ok := types.ParseGitCommand(inner)
+		if !ok {
+			continue
+		}
+		switch git.Subcommand {
+		case "switch", "checkout":
+			if target, ok := switchTarget(git); ok {
+				branch, switched = target, true
+			}
+		case "add":
+			adds = append(adds, parseAdd(git, cwd))
+		}
+		if !commitCommands[git.Subcommand] || hasArg(git.Args, "--dry-run") {
+			continue
+		}
+
+		subject := strings.Join(c.Args, " ")
+		dir := git.WorkDir(cwd)
+		if !filepath.IsAbs(dir) {
+			return &violation{reason: "Could not determine the directory the commit runs in, so it cannot be checked for commits to protected branches. Command: " + subject, unresolved: true}
+		}
+		repo, ok := types.FindGitRepo(dir)
+		if !ok {
+			continue
+		}
+		current := branch
+		if !switched {
+			current = repo.Branch()
+		}
+		if p.protected(current) {
+			return &violation{reason: fmt.Sprintf("Committing to the protected branch %s is not allowed; %s and commit there. Command: %s", current, featureBranchHint, subject)}
+		}
+		if git.Subcommand != "commit" || len(p.protectedPaths) == 0 {
+			continue
+		}
+		if files := p.protectedChanges(committedFiles(dir, git, adds)); len(files) > 0 {
+			return &violation{reason: fmt.Sprintf("The commit includes changes to protected paths: %s. These changes need review by a person; "+
+				"unstage them with `git restore --staged <path>` and leave them uncommitted. Command: %s", strings.Join(files, ", "), subject)}
+		}
+	}
+	return nil
+}
+
+// gitPattern和commitPattern 在无法解析的命令中查找git提交
+var (
+	gitPattern    = regexp.MustCompile(`\bgit\b`)
+	commitPattern = regexp.MustCompile(`\b(commit|cherry-pick|revert|am)\b`)
+)
+
+// changeDir 返回cd到target后的工作目录，无法确定时返回空字符串
+func changeDir(target, cwd string) string {
+	if target == "-" {
+		return ""
+	}
+	expanded, ok := types.ExpandVariables(target, cwd, nil)
+	if !ok {
+		return ""
+	}
+	dir, ok := types.ResolvePath(expanded, cwd)
+	if !ok {
+		return ""
+	}
+	return dir
+}
+
+// switchCreateOptions git switch/checkout中创建并切换到新分支的选项
+var switchCreateOptions = map[string]map[string]bool{
+	"switch":   {"-c": true, "-C": true, "--create": true, "--force-create": true},
+	"checkout": {"-b": true, "-B": true},
+}
+
+// switchTarget 返回git switch/checkout切换到的分支
+// checkout只识别-b/-B，因为 git checkout <name> 也可能是恢复文件
+func switchTarget(git types.GitCommand) (string, bool) {
+	create := switchCreateOptions[git.Subcommand]
+	for i, arg := range git.Args {
+		if create[arg] && i+1 < len(git.Args) {
+			return git.Args[i+1], true
+		}
+	}
+	if git.Subcommand != "switch" {
+		return "", false
+	}
+	for _, arg := range git.Args {
+		if !strings.HasPrefix(arg, "-") {
+			return arg, true
+		}
+	}
+	return "", false
+}
+
+// protectedChanges 返回files中匹配protectedPaths的文件
+func (p *Plugin) protectedChanges(files []string) []string {
+	var matched []string
+	seen := make(map[string]bool)
+	for _, file := range files {
+		if seen[file] {
+			continue
+		}
+		seen[file] = true
+		for _, glob := range p.protectedPaths {
+			if types.MatchGlob(glob, file) {
+				matched = append(matched, file)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+func hasArg(args []string, want string) bool {
+	for _, arg := range args {
+		if arg == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"claude-hooks/types"
+	"claude-hooks/types/hooktest"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testdata中的夹具不在git仓库中，除无法确定的提交外都应该放行；仓库相关的行为由TestRepository覆盖
+func TestFixtures(t *testing.T) {
+	hooktest.Run(t, New, "testdata")
+}
+
+func TestConfigureErrors(t *testing.T) {
+	for _, config := range []string{
+		`{"protectedBranches": ["["]}`,
+		`{"protectedPaths": [""]}`,
+		`{"allowFiles": ["a/["]}`,
+		`{"branches": ["main"]}`,
+	} {
+		if err := New().(*Plugin).Configure(json.RawMessage(config)); err == nil {
+			t.Errorf("Configure(%s) succeeded, want error", config)
+		}
+	}
+}
+
+// newRepo 在临时目录中创建一个有初始提交的仓库，当前分支为branch
+func newRepo(t *testing.T, branch string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	writeFile(t, dir, "README.md", "# test\n")
+	writeFile(t, dir, ".github/workflows/ci.yml", "on: push\n")
+	writeFile(t, dir, "go.mod", "module test\n")
+	runGit(t, dir, "init", "-q", "-b", "main")
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "initial")
+	if branch != "main" {
+		runGit(t, dir, "switch", "-q", "-c", branch)
+	}
+	return dir
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+func TestRepository(t *testing.T) {
+	const config = `{"protectedPaths": [".github/workflows/**", "go.mod"], "allowFiles": ["docs/**"]}`
+
+	tests := []struct {
+		name   string
+		branch string
+		// 在检查前修改工作树
+		setup func(t *testing.T, dir string)
+		tool  string
+		input map[string]any
+		// 期望阻止原因中包含的内容，为空表示允许
+		want string
+	}{
+		{"edit on main", "main", nil, "Edit", map[string]any{"file_path": "README.md"}, "The branch main is protected; create a feature branch first"},
+		{"write new file on main", "main", nil, "Write", map[string]any{"file_path": "pkg/new/file.go"}, "The branch main is protected"},
+		{"allowed file on main", "main", nil, "Write", map[string]any{"file_path": "docs/notes.md"}, ""},
+		{"edit on feature branch", "feature/x", nil, "Edit", map[string]any{"file_path": "README.md"}, ""},
+		{"edit outside repository", "main", nil, "Write", map[string]any{"file_path": "/nonexistent/file.go"}, ""},
+		{"commit on main", "main", nil, "Bash", map[string]any{"command": "git commit -m 'fix typo'"}, "Committing to the protected branch main is not allowed"},
+		{"commit with -C on main", "main", nil, "Bash", map[string]any{"command": "cd /tmp && git -C $REPO commit -am x"}, "protected branch main"},
+		{"cherry-pick on main", "main", nil, "Bash", map[string]any{"command": "git cherry-pick abc123"}, "protected branch main"},
+		{"dry run on main", "main", nil, "Bash", map[string]any{"command": "git commit --dry-run"}, ""},
+		{"switch before commit", "main", nil, "Bash", map[string]any{"command": "git switch -c fix/typo && git commit -am 'fix typo'"}, ""},
+		{"checkout -b before commit", "main", nil, "Bash", map[string]any{"command": "git checkout -b fix/typo; git commit -m x"}, ""},
+		{"switch to main before commit", "feature/x", nil, "Bash", map[string]any{"command": "git switch main && git commit -m x"}, "protected branch main"},
+		{"commit on feature branch", "feature/x", nil, "Bash", map[string]any{"command": "git commit -m x"}, ""},
+		{"cd to repository on main", "feature/x", nil, "Bash", map[string]any{"command": "cd $OTHER && git commit -m x"}, "protected branch main"},
+		{"cd to subdirectory on main", "feature/x", nil, "Bash", map[string]any{"command": "cd $OTHER; cd src && git commit -m x"}, "protected branch main"},
+		{"cd back before commit", "main", nil, "Bash", map[string]any{"command": "cd $OTHER && cd - && git commit -m x"}, "Could not determine the directory the commit runs in"},
+		{"cd outside repository", "main", nil, "Bash", map[string]any{"command": "cd /nonexistent && git commit -m x"}, ""},
+		{"apostrophe in message on main", "main", nil, "Bash", map[string]any{"command": "git commit -m 'don't break'"}, "Could not parse the command"},
+		{"staged protected path", "feature/x", func(t *testing.T, dir string) {
+			writeFile(t, dir, ".github/workflows/ci.yml", "on: [push, pull_request]\n")
+			runGit(t, dir, "add", ".github")
+		}, "Bash", map[string]any{"command": "git commit -m 'update ci'"}, "protected paths: .github/workflows/ci.yml"},
+		{"commit -a with modified protected path", "feature/x", func(t *testing.T, dir string) {
+			writeFile(t, dir, "go.mod", "module test\n\ngo 1.22\n")
+		}, "Bash", map[string]any{"command": "git commit -am 'bump go'"}, "protected paths: go.mod"},
+		{"unstaged protected path", "feature/x", func(t *testing.T, dir string) {
+			writeFile(t, dir, "go.mod", "module test\n\ngo 1.22\n")
+			writeFile(t, dir, "README.md", "# changed\n")
+			runGit(t, dir, "add", "README.md")
+		}, "Bash", map[string]any{"command": "git commit -m 'docs'"}, ""},
+		{"add in same command", "feature/x", func(t *testing.T, dir string) {
+			writeFile(t, dir, ".github/workflows/release.yml", "on: release\n")
+		}, "Bash", map[string]any{"command": "git add .github && git commit -m 'add release'"}, "protected paths: .github/workflows/release.yml"},
+		{"add all in same command", "feature/x", func(t *testing.T, dir string) {
+			writeFile(t, dir, "go.mod", "module test\n\ngo 1.22\n")
+		}, "Bash", map[string]any{"command": "git add -A; git commit -m wip"}, "protected paths: go.mod"},
+		{"add other path in same command", "feature/x", func(t *testing.T, dir string) {
+			writeFile(t, dir, "go.mod", "module test\n\ngo 1.22\n")
+			writeFile(t, dir, "main.go", "package main\n")
+		}, "Bash", map[string]any{"command": "git add main.go && git commit -m 'add main'"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newRepo(t, tt.branch)
+			if tt.setup != nil {
+				tt.setup(t, dir)
+			}
+			plugin := New().(*Plugin)
+			if err := plugin.Configure(json.RawMessage(config)); err != nil {
+				t.Fatal(err)
+			}
+
+			input := make(map[string]any)
+			for k, v := range tt.input {
+				if s, ok := v.(string); ok {
+					if strings.Contains(s, "$OTHER") {
+						s = strings.ReplaceAll(s, "$OTHER", newRepo(t, "main"))
+					}
+					v = strings.ReplaceAll(s, "$REPO", dir)
+				}
+				input[k] = v
+			}
+			arg := types.ToolInput{ToolName: tt.tool, ToolInput: input}
+			arg.Cwd = dir
+			output, err := plugin.PreToolUse(arg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			reason := outputReason(output)
+			if tt.want == "" {
+				if output != nil {
+					t.Errorf("blocked: %s", reason)
+				}
+				return
+			}
+			if output == nil {
+				t.Fatalf("not blocked, want reason containing %q", tt.want)
+			}
+			if !strings.Contains(reason, tt.want) {
+				t.Errorf("reason = %q, want it to contain %q", reason, tt.want)
+			}
+		})
+	}
+}
+
+// outputReason 返回阻止或确认的原因
+func outputReason(output *types.PreToolUseOutput) string {
+	switch {
+	case output == nil:
+		return ""
+	case output.Reason != nil:
+		return *output.Reason
+	case output.HookSpecificOutput != nil:
+		return output.HookSpecificOutput.PermissionDecisionReason
+	}
+	return ""
+}
+
+func TestCommitsAll(t *testing.T) {
+	tests := []struct {
+		args string
+		want bool
+	}{
+		{"-am msg", true},
+		{"-a -m msg", true},
+		{"--all", true},
+		{"-m -a", false},
+		{"-ma", false},
+		{"-m msg -- a", false},
+		{"--amend", false},
+	}
+	for _, tt := range tests {
+		if got := commitsAll(strings.Fields(tt.args)); got != tt.want {
+			t.Errorf("commitsAll(%s) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"claude-hooks/types"
+	"os/exec"
+	"strings"
+)
+
+// gitAdd 同一条命令中先于git commit执行的git add
+type gitAdd struct {
+	// git add的工作目录
+	dir string
+	// -A/--all：暂存所有修改，包括未跟踪的文件
+	all bool
+	// -u/--update：暂存所有已跟踪文件的修改
+	update    bool
+	pathspecs []string
+}
+
+// parseAdd 解析git add的参数，cwd为命令的工作目录
+func parseAdd(git types.GitCommand, cwd string) gitAdd {
+	add := gitAdd{dir: git.WorkDir(cwd)}
+	endOfOptions := false
+	for _, arg := range git.Args {
+		switch {
+		case endOfOptions || !strings.HasPrefix(arg, "-"):
+			add.pathspecs = append(add.pathspecs, arg)
+		case arg == "--":
+			endOfOptions = true
+		case arg == "--all":
+			add.all = true
+		case arg == "--update":
+			add.update = true
+		case !strings.HasPrefix(arg, "--"):
+			add.all = add.all || strings.Contains(arg, "A")
+			add.update = add.update || strings.Contains(arg, "u")
+		}
+	}
+	return add
+}
+
+// committedFiles 返回git commit会提交的文件，路径相对仓库根目录
+// 包括已暂存的文件、-a提交的已跟踪文件，以及同一条命令中先前git add的文件
+// git不可用时返回已知的部分
+func committedFiles(dir string, commit types.GitCommand, adds []gitAdd) []string {
+	files := gitFiles(dir, "diff", "--cached", "--name-only", "-z")
+
+	if commitsAll(commit.Args) {
+		files = append(files, gitFiles(dir, "ls-files", "--modified", "--deleted", "--full-name", "-z", "--", ":/")...)
+	}
+
+	for _, add := range adds {
+		switch {
+		case add.all && len(add.pathspecs) == 0:
+			files = append(files, gitFiles(add.dir, "ls-files", "--modified", "--deleted", "--others", "--exclude-standard", "--full-name", "-z", "--", ":/")...)
+		case add.update && len(add.pathspecs) == 0:
+			files = append(files, gitFiles(add.dir, "ls-files", "--modified", "--deleted", "--full-name", "-z", "--", ":/")...)
+		case len(add.pathspecs) > 0:
+			args := append([]string{"ls-files", "--modified", "--deleted", "--others", "--exclude-standard", "--full-name", "-z", "--"}, add.pathspecs...)
+			files = append(files, gitFiles(add.dir, args...)...)
+		}
+	}
+	return files
+}
+
+// commitValueOptions 需要参数值的git commit短选项，之后的字符是选项值
+const commitValueOptions = "mFCct"
+
+// commitsAll 判断git commit是否有-a/--all，-a可以与其他短选项合并，如 -am
+func commitsAll(args []string) bool {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return false
+		case arg == "--all":
+			return true
+		case strings.HasPrefix(arg, "--") || !strings.HasPrefix(arg, "-"):
+		default:
+			for j, flag := range arg[1:] {
+				if flag == 'a' {
+					return true
+				}
+				if strings.ContainsRune(commitValueOptions, flag) {
+					if j == len(arg)-2 {
+						// -m message
+						i++
+					}
+					break
+				}
+			}
+		}
+	}
+	return false
+}
+
+// gitFiles 在dir中执行git命令，返回以NUL分隔的输出中的文件名，失败时返回nil
+func gitFiles(dir string, args ...string) []string {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return nil
+	}
+	var files []string
+	for _, file := range strings.Split(stdout.String(), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
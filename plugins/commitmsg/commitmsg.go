@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"claude-hooks/types"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+type Plugin struct {
+	types.UnimplementedPlugin
+	// 是否要求Conventional Commits格式
+	conventional bool
+	// Conventional Commits允许的类型
+	types []string
+	// 提交信息必须匹配的规则，为nil表示不检查
+	pattern *Rule
+	// 标题行的最大长度，0表示不限制
+	maxSubjectLength int
+	// 提交信息中禁止出现的内容
+	forbid           []*Rule
+	allowNoVerify    bool
+	allowAmendPushed bool
+}
+
+func New() types.IPlugin {
+	return &Plugin{
+		conventional:     true,
+		types:            defaultTypes,
+		maxSubjectLength: defaultMaxSubjectLength,
+		forbid:           []*Rule{coAuthorRule},
+	}
+}
+
+func (p *Plugin) GetMetadata() types.PluginMetadata {
+	return types.PluginMetadata{
+		Description: "检查git提交信息和提交方式",
+		Matchers: []types.Matcher{
+			types.OnPreToolUse(types.Tools("Bash")),
+		},
+		APIVersion:   types.APIVersion,
+		ConfigSchema: configSchema,
+	}
+}
+
+func (p *Plugin) PreToolUse(arg types.ToolInput) (*types.PreToolUseOutput, error) {
+	var ret types.PreToolUseOutput
+
+	input, err := arg.AsBash()
+	if err != nil {
+		return nil, err
+	}
+	violations, err := p.checkCommand(input.Command, arg.Cwd)
+	if err != nil {
+		// 无法解析时不能检查提交信息，不依赖可能未安装的bashguard，含有git提交的命令交给用户确认
+		if gitPattern.MatchString(input.Command) && commitPattern.MatchString(input.Command) {
+			return ret.Ask(fmt.Sprintf("Could not parse the command (%v), so it cannot be checked against the repository's commit policy. Command: %s", err, input.Command)), nil
+		}
+		return nil, nil
+	}
+	if len(violations) == 0 {
+		return nil, nil
+	}
+	return ret.Approve(false, reason(violations)), nil
+}
+
+// reason 返回阻止原因，列出所有违反的规则
+func reason(violations []string) string {
+	var sb strings.Builder
+	sb.WriteString("The command breaks the repository's commit policy:")
+	for _, v := range violations {
+		fmt.Fprintf(&sb, "\n  - %s", v)
+	}
+	sb.WriteString("\nFix the command and run it again.")
+	return sb.String()
+}
+
+// gitPattern和commitPattern 在无法解析的命令中查找git提交和跳过hooks的选项
+var (
+	gitPattern    = regexp.MustCompile(`\bgit\b`)
+	commitPattern = regexp.MustCompile(`\bcommit\b|--no-verify|hooksPath`)
+)
+
+// checkCommand 检查Bash命令中的git命令，返回违反的规则；命令无法解析时返回错误
+func (p *Plugin) checkCommand(command, cwd string) ([]string, error) {
+	commands, err := types.ParseShell(command)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []string
+	// 命令中已经创建了新提交的目录，之后的 --amend 只修改这个新提交
+	committed := make(map[string]bool)
+	for _, c := range commands {
+		c = c.Unwrap()
+		git, ok := types.ParseGitCommand(c)
+		if !ok {
+			continue
+		}
+		var commit commit
+		if git.Subcommand == "commit" {
+			commit = parseCommit(git.Args)
+			if commit.dryRun {
+				// --dry-run不创建提交，也不运行hooks
+				continue
+			}
+		}
+		if !p.allowNoVerify {
+			if v := skipsHooks(c, git); v != "" {
+				violations = append(violations, v)
+			}
+		}
+		if git.Subcommand != "commit" {
+			continue
+		}
+
+		dir := git.WorkDir(cwd)
+		if commit.noVerify == "-n" && !p.allowNoVerify {
+			violations = append(violations, noVerifyViolation("-n (--no-verify)"))
+		}
+		if commit.amend && !p.allowAmendPushed && !committed[dir] {
+			if remote := pushedTo(dir); remote != "" {
+				violations = append(violations, fmt.Sprintf("--amend would rewrite HEAD, which is already pushed to %s; "+
+					"make a new commit instead of rewriting published history", remote))
+			}
+		}
+		committed[dir] = true
+
+		message, ok := commit.message(dir, command)
+		if !ok {
+			continue
+		}
+		violations = append(violations, p.checkForbidden(message.text)...)
+		if message.complete {
+			violations = append(violations, p.checkFormat(message.text)...)
+		}
+	}
+	return violations, nil
+}
+
+func noVerifyViolation(option string) string {
+	return fmt.Sprintf("%s skips the repository's git hooks; run the command without it and fix what the hooks report", option)
+}
+
+// skipsHooks 检查跳过git hooks的选项：任何子命令的--no-verify，以及用 -c core.hooksPath=... 替换hooks目录
+func skipsHooks(c types.ShellCommand, git types.GitCommand) string {
+	for _, arg := range git.Args {
+		if arg == "--" {
+			break
+		}
+		if arg == "--no-verify" {
+			return noVerifyViolation(arg)
+		}
+	}
+	// 子命令之前的全局选项
+	global := c.Args[1 : len(c.Args)-len(git.Args)-1]
+	for i, arg := range global {
+		if arg == "-c" && i+1 < len(global) && strings.HasPrefix(strings.ToLower(global[i+1]), "core.hookspath=") {
+			return noVerifyViolation("-c " + global[i+1])
+		}
+	}
+	return ""
+}
+
+// pushedTo 返回包含dir所在仓库HEAD的远程分支，HEAD未推送或git不可用时返回空字符串
+func pushedTo(dir string) string {
+	cmd := exec.Command("git", "-C", dir, "branch", "--remotes", "--contains", "HEAD", "--format=%(refname:short)")
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return ""
+	}
+	for _, branch := range strings.Split(stdout.String(), "\n") {
+		// origin/HEAD是远程默认分支的别名
+		if branch != "" && !strings.HasSuffix(branch, "/HEAD") {
+			return branch
+		}
+	}
+	return ""
+}
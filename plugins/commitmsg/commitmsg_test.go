@@ -0,0 +1,166 @@
+package main
+
+import (
+	"claude-hooks/types"
+	"claude-hooks/types/hooktest"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFixtures(t *testing.T) {
+	hooktest.Run(t, New, "testdata")
+}
+
+// testConfig testdata/config中的夹具使用的配置
+const testConfig = `{
+  "pattern": {"regex": "^\\[[A-Z]+-\\d+\\] ", "message": "Start the subject with the ticket ID, e.g. [ABC-123] Fix login"},
+  "maxSubjectLength": 50,
+  "forbid": [{"regex": "(?i)generated with"}],
+  "allowNoVerify": true
+}`
+
+func TestConfiguredFixtures(t *testing.T) {
+	hooktest.RunConfig(t, New, "testdata/config", json.RawMessage(testConfig))
+}
+
+func TestConfigureErrors(t *testing.T) {
+	for _, config := range []string{
+		`{"pattern": {"regex": "("}}`,
+		`{"pattern": {"message": "no regex"}}`,
+		`{"forbid": [{"regex": ""}]}`,
+		`{"types": ["feat", "fix(core)"]}`,
+		`{"maxSubjectLength": -1}`,
+		`{"style": "conventional"}`,
+	} {
+		if err := New().(*Plugin).Configure(json.RawMessage(config)); err == nil {
+			t.Errorf("Configure(%s) succeeded, want error", config)
+		}
+	}
+}
+
+func TestParseCommit(t *testing.T) {
+	tests := []struct {
+		args     string
+		messages []string
+		file     string
+		amend    bool
+		noVerify string
+	}{
+		{"-m msg", []string{"msg"}, "", false, ""},
+		{"-am msg", []string{"msg"}, "", false, ""},
+		{"-mmsg", []string{"msg"}, "", false, ""},
+		{"--message=a -m b", []string{"a", "b"}, "", false, ""},
+		{"-nm msg", []string{"msg"}, "", false, "-n"},
+		{"--amend --no-edit", nil, "", true, ""},
+		{"-F msg.txt --no-verify", nil, "msg.txt", false, "--no-verify"},
+		{"--file=- --author x", nil, "-", false, ""},
+		{"-C HEAD -- -m", nil, "", false, ""},
+	}
+	for _, tt := range tests {
+		c := parseCommit(strings.Fields(tt.args))
+		if strings.Join(c.messages, "|") != strings.Join(tt.messages, "|") || c.file != tt.file || c.amend != tt.amend || c.noVerify != tt.noVerify {
+			t.Errorf("parseCommit(%s) = %+v", tt.args, c)
+		}
+	}
+}
+
+func TestExpandMessage(t *testing.T) {
+	tests := []struct {
+		value    string
+		want     string
+		complete bool
+	}{
+		{"fix: typo", "fix: typo", true},
+		{"$(cat <<'EOF'\nfix: typo\n\nBody\nEOF\n)", "fix: typo\n\nBody", true},
+		{"$(cat <<EOF\nfix: typo\n  EOF\n  )", "fix: typo", true},
+		{"$(cat <<-\"END\"\n\tfix: typo\n\tEND\n)", "fix: typo", true},
+		{"$(cat <<'EOF'\nfix: typo\n)", "$(cat <<'EOF'\nfix: typo\n)", false},
+		{"$MSG", "$MSG", false},
+		{"fix: `date`", "fix: `date`", false},
+		{"fix: cost is $5", "fix: cost is $5", true},
+	}
+	for _, tt := range tests {
+		got, complete := expandMessage(tt.value)
+		if got != tt.want || complete != tt.complete {
+			t.Errorf("expandMessage(%q) = %q, %v, want %q, %v", tt.value, got, complete, tt.want, tt.complete)
+		}
+	}
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+// newRepo 创建一个有初始提交的仓库，pushed为true时初始提交已推送到origin/main
+func newRepo(t *testing.T, pushed bool) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := filepath.Join(t.TempDir(), "repo")
+	runGit(t, filepath.Dir(dir), "init", "-q", "-b", "main", dir)
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# test\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "initial")
+	if pushed {
+		remote := filepath.Join(filepath.Dir(dir), "remote.git")
+		runGit(t, dir, "init", "-q", "--bare", remote)
+		runGit(t, dir, "remote", "add", "origin", remote)
+		runGit(t, dir, "push", "-q", "origin", "main")
+	}
+	return dir
+}
+
+func TestRepository(t *testing.T) {
+	tests := []struct {
+		name    string
+		pushed  bool
+		command string
+		// 期望阻止原因中包含的内容，为空表示允许
+		want string
+	}{
+		{"amend pushed commit", true, "git commit --amend --no-edit", "--amend would rewrite HEAD, which is already pushed to origin/main"},
+		{"amend pushed commit with -C", true, "cd / && git -C $REPO commit --amend -m 'fix: typo'", "already pushed to origin/main"},
+		{"amend local commit", false, "git commit --amend --no-edit", ""},
+		{"amend new commit", true, "git commit -m 'fix: typo' && git commit --amend --no-edit", ""},
+		{"message file", false, "git commit -F msg.txt", "does not follow Conventional Commits"},
+		{"missing message file", false, "git commit -F missing.txt", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newRepo(t, tt.pushed)
+			if err := os.WriteFile(filepath.Join(dir, "msg.txt"), []byte("Update things\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			arg := types.ToolInput{ToolName: "Bash", ToolInput: map[string]any{"command": strings.ReplaceAll(tt.command, "$REPO", dir)}}
+			arg.Cwd = dir
+			output, err := New().(*Plugin).PreToolUse(arg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.want == "" {
+				if output != nil {
+					t.Errorf("blocked: %s", *output.Reason)
+				}
+				return
+			}
+			if output == nil || output.Reason == nil {
+				t.Fatalf("not blocked, want reason containing %q", tt.want)
+			}
+			if !strings.Contains(*output.Reason, tt.want) {
+				t.Errorf("reason = %q, want it to contain %q", *output.Reason, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// commit 解析后的git commit参数
+type commit struct {
+	// -m/--message的值，多个-m按段落拼接
+	messages []string
+	// -F/--file的值，"-"表示从标准输入读取
+	file   string
+	amend  bool
+	dryRun bool
+	// 跳过git hooks的选项，-n或--no-verify
+	noVerify string
+	// --trailer的值
+	trailers []string
+}
+
+// commitValueOptions 需要参数值的git commit长选项
+var commitValueOptions = map[string]bool{
+	"--message": true, "--file": true, "--author": true, "--date": true, "--reuse-message": true,
+	"--reedit-message": true, "--fixup": true, "--squash": true, "--template": true, "--cleanup": true,
+	"--trailer": true, "--pathspec-from-file": true,
+}
+
+// shortValueOptions 需要参数值的git commit短选项，之后的字符是选项值
+const shortValueOptions = "mFCct"
+
+// parseCommit 解析git commit的参数
+func parseCommit(args []string) commit {
+	var c commit
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			continue
+		}
+
+		if strings.HasPrefix(arg, "--") {
+			option, value, hasValue := strings.Cut(arg, "=")
+			if commitValueOptions[option] && !hasValue && i+1 < len(args) {
+				i++
+				value = args[i]
+			}
+			c.setOption(option, value)
+			continue
+		}
+
+		// 短选项可以合并，如 -am message、-nm message、-mmessage
+		for j, flag := range arg[1:] {
+			if !strings.ContainsRune(shortValueOptions, flag) {
+				c.setOption("-"+string(flag), "")
+				continue
+			}
+			value := arg[j+2:]
+			if value == "" && i+1 < len(args) {
+				i++
+				value = args[i]
+			}
+			c.setOption("-"+string(flag), value)
+			break
+		}
+	}
+	return c
+}
+
+func (c *commit) setOption(option, value string) {
+	switch option {
+	case "-m", "--message":
+		c.messages = append(c.messages, value)
+	case "-F", "--file":
+		c.file = value
+	case "--amend":
+		c.amend = true
+	case "--dry-run":
+		c.dryRun = true
+	case "-n", "--no-verify":
+		c.noVerify = option
+	case "--trailer":
+		c.trailers = append(c.trailers, value)
+	}
+}
+
+// message 提交信息
+type message struct {
+	text string
+	// 信息的全部内容已知，可以检查格式；否则只检查禁止的内容
+	complete bool
+}
+
+// message 返回提交信息，dir为git命令的工作目录，command为整条Bash命令
+// 没有新的提交信息时返回false
+func (c commit) message(dir, command string) (message, bool) {
+	var m message
+	switch {
+	case len(c.messages) > 0:
+		parts := make([]string, len(c.messages))
+		m.complete = true
+		for i, value := range c.messages {
+			text, ok := expandMessage(value)
+			parts[i] = text
+			m.complete = m.complete && ok
+		}
+		m.text = strings.Join(parts, "\n\n")
+	case c.file == "-":
+		// 来自标准输入（通常是here-document），只能在整条命令中查找禁止的内容
+		m.text = command
+	case c.file != "":
+		file := c.file
+		if !filepath.IsAbs(file) && dir != "" {
+			file = filepath.Join(dir, file)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return message{}, false
+		}
+		m.text, m.complete = string(data), true
+	default:
+		// 复用已有的提交信息，如 -C、--fixup、--amend --no-edit
+		return message{}, false
+	}
+
+	for _, trailer := range c.trailers {
+		m.text += "\n\n" + trailer
+	}
+	return m, true
+}
+
+var (
+	// heredocStart $(cat <<'EOF' 形式的命令替换的开头
+	heredocStart = regexp.MustCompile(`^\$\(\s*cat\s*<<(-?)\s*['"]?(\w+)['"]?[ \t]*\n`)
+	// dynamicText 变量和命令替换，其值在执行前无法得知
+	dynamicText = regexp.MustCompile("\\$[A-Za-z_{(]|`")
+)
+
+// expandMessage 展开-m的值；值为 "$(cat <<'EOF' ... EOF)" 时返回here-document的内容
+// 值包含其他变量或命令替换时返回false
+func expandMessage(value string) (string, bool) {
+	match := heredocStart.FindStringSubmatch(value)
+	if match == nil {
+		return value, !dynamicText.MatchString(value)
+	}
+	stripTabs, delimiter := match[1] == "-", match[2]
+	lines := strings.Split(value[len(match[0]):], "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != delimiter {
+			continue
+		}
+		if strings.TrimSpace(strings.Join(lines[i+1:], "\n")) != ")" {
+			break
+		}
+		body := lines[:i]
+		if stripTabs {
+			for j := range body {
+				body[j] = strings.TrimLeft(body[j], "\t")
+			}
+		}
+		return strings.Join(body, "\n"), true
+	}
+	return value, false
+}
+
+// generatedSubject git生成的提交信息标题的前缀，不检查格式
+var generatedSubject = []string{"Merge ", "Revert \"", "fixup! ", "squash! ", "amend! "}
+
+// conventionalSubject Conventional Commits的标题行：type(scope)!: description
+var conventionalSubject = regexp.MustCompile(`^(\w[\w-]*)(\([^()\r\n]+\))?(!)?: \S`)
+
+// subject 返回提交信息的标题行和正文是否与标题之间有空行
+// 与git一样忽略开头的空行和注释行
+func subject(text string) (string, bool) {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, strings.TrimRight(line, " \t\r"))
+		}
+	}
+	if len(lines) == 0 {
+		return "", true
+	}
+	return lines[0], len(lines) == 1 || lines[1] == ""
+}
+
+// checkFormat 检查提交信息的格式，返回违反的规则
+func (p *Plugin) checkFormat(text string) []string {
+	var violations []string
+	first, separated := subject(text)
+	if first == "" {
+		return []string{"The commit message is empty; describe the change"}
+	}
+	for _, prefix := range generatedSubject {
+		if strings.HasPrefix(first, prefix) {
+			return nil
+		}
+	}
+
+	if p.conventional {
+		if v := p.checkConventional(first); v != "" {
+			violations = append(violations, v)
+		}
+	}
+	if p.pattern != nil && !p.pattern.re.MatchString(text) {
+		v := fmt.Sprintf("The commit message must match the pattern %s", p.pattern.Regex)
+		if p.pattern.Message != "" {
+			v = fmt.Sprintf("%s (pattern %s)", strings.TrimSuffix(p.pattern.Message, "."), p.pattern.Regex)
+		}
+		violations = append(violations, v)
+	}
+	if length := len([]rune(first)); p.maxSubjectLength > 0 && length > p.maxSubjectLength {
+		violations = append(violations, fmt.Sprintf("The subject line is %d characters long; keep it within %d and put details in the body", length, p.maxSubjectLength))
+	}
+	if !separated {
+		violations = append(violations, "Separate the subject line from the body with a blank line")
+	}
+	return violations
+}
+
+// checkConventional 检查标题行是否符合Conventional Commits
+func (p *Plugin) checkConventional(first string) string {
+	allowed := strings.Join(p.types, ", ")
+	match := conventionalSubject.FindStringSubmatch(first)
+	if match == nil {
+		return fmt.Sprintf("The subject %q does not follow Conventional Commits; write it as `<type>(<optional scope>): <description>` with a type from: %s", first, allowed)
+	}
+	for _, t := range p.types {
+		if strings.EqualFold(t, match[1]) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("The commit type %q is not allowed; use one of: %s", match[1], allowed)
+}
+
+// checkForbidden 检查提交信息中禁止的内容，返回违反的规则
+func (p *Plugin) checkForbidden(text string) []string {
+	var violations []string
+	for _, rule := range p.forbid {
+		loc := rule.re.FindStringIndex(text)
+		if loc == nil {
+			continue
+		}
+		found := matchedLine(text, loc)
+		if rule.Message == "" {
+			violations = append(violations, fmt.Sprintf("The commit message must not contain text matching %s; remove %q", rule.Regex, found))
+		} else {
+			violations = append(violations, fmt.Sprintf("%s; remove %q", strings.TrimSuffix(rule.Message, "."), found))
+		}
+	}
+	return violations
+}
+
+// maxFoundLength 报告的匹配内容的最大长度
+const maxFoundLength = 80
+
+// matchedLine 返回匹配所在的行，过长时截断
+func matchedLine(text string, loc []int) string {
+	start := strings.LastIndex(text[:loc[0]], "\n") + 1
+	end := len(text)
+	if i := strings.Index(text[loc[1]:], "\n"); i >= 0 {
+		end = loc[1] + i
+	}
+	line := strings.TrimSpace(text[start:end])
+	if runes := []rune(line); len(runes) > maxFoundLength {
+		line = string(runes[:maxFoundLength]) + "..."
+	}
+	return line
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"claude-hooks/types"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Config commitmsg插件的配置，从 .claude/plugins/commitmsg.json 读取
+//
+//	{
+//	  "types": ["feat", "fix", "docs", "chore"],
+//	  "maxSubjectLength": 50,
+//	  "forbid": [{"regex": "(?i)generated with", "message": "Do not mention tooling in commit messages"}]
+//	}
+//
+// 设置pattern后不再检查Conventional Commits格式：
+//
+//	{"pattern": {"regex": "^\\[[A-Z]+-\\d+\\] ", "message": "Start the subject with the ticket, e.g. [ABC-123] Fix login"}}
+type Config struct {
+	// 是否要求Conventional Commits格式，设置了pattern时默认为false，否则默认为true
+	Conventional *bool `json:"conventional,omitempty"`
+	// Conventional Commits允许的类型，默认为defaultTypes
+	Types []string `json:"types,omitempty"`
+	// 提交信息必须匹配的正则表达式
+	Pattern *Rule `json:"pattern,omitempty"`
+	// 标题行的最大长度，默认为72，0表示不限制
+	MaxSubjectLength *int `json:"maxSubjectLength,omitempty"`
+	// 提交信息中禁止出现的内容，在内置的Co-authored-by规则之外
+	Forbid []Rule `json:"forbid,omitempty"`
+	// 允许Co-authored-by trailer
+	AllowCoAuthors bool `json:"allowCoAuthors,omitempty"`
+	// 允许--no-verify等跳过git hooks的选项
+	AllowNoVerify bool `json:"allowNoVerify,omitempty"`
+	// 允许--amend修改已推送的提交
+	AllowAmendPushed bool `json:"allowAmendPushed,omitempty"`
+}
+
+// Rule 匹配提交信息的正则表达式
+type Rule struct {
+	Regex string `json:"regex"`
+	// 违反规则时给Claude的说明
+	Message string `json:"message,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// compile 编译正则表达式，name用于错误信息
+func (r *Rule) compile(name string) error {
+	if r.Regex == "" {
+		return fmt.Errorf("%s: regex is required", name)
+	}
+	re, err := regexp.Compile(r.Regex)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	r.re = re
+	return nil
+}
+
+// configSchema commitmsg.json的格式
+var configSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "conventional": {"type": "boolean", "description": "Require Conventional Commits (default true unless pattern is set)"},
+    "types": {"type": "array", "items": {"type": "string"}, "description": "Allowed Conventional Commits types"},
+    "pattern": {"$ref": "#/$defs/rule", "description": "Regular expression the commit message must match"},
+    "maxSubjectLength": {"type": "integer", "minimum": 0, "description": "Maximum subject line length (default 72, 0 for no limit)"},
+    "forbid": {"type": "array", "items": {"$ref": "#/$defs/rule"}, "description": "Text that must not appear in commit messages"},
+    "allowCoAuthors": {"type": "boolean", "description": "Allow Co-authored-by trailers"},
+    "allowNoVerify": {"type": "boolean", "description": "Allow --no-verify and other ways of skipping git hooks"},
+    "allowAmendPushed": {"type": "boolean", "description": "Allow --amend when HEAD is already pushed"}
+  },
+  "additionalProperties": false,
+  "$defs": {
+    "rule": {
+      "type": "object",
+      "properties": {
+        "regex": {"type": "string", "description": "Go regular expression matched against the commit message"},
+        "message": {"type": "string", "description": "Explanation given when the rule is violated"}
+      },
+      "required": ["regex"],
+      "additionalProperties": false
+    }
+  }
+}`)
+
+// defaultTypes Conventional Commits的默认类型
+var defaultTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+// defaultMaxSubjectLength 默认的标题行最大长度
+const defaultMaxSubjectLength = 72
+
+// coAuthorRegex 匹配Co-authored-by trailer，包括 --trailer 使用的 key=value 形式
+const coAuthorRegex = `(?im)^[ \t]*co-authored-by[ \t]*[:=]`
+
+// coAuthorRule 内置规则，禁止Co-authored-by trailer
+var coAuthorRule = &Rule{
+	Regex:   coAuthorRegex,
+	Message: "Co-authored-by trailers are not allowed",
+	re:      regexp.MustCompile(coAuthorRegex),
+}
+
+// Configure 编译提交信息的格式和禁止的内容，没有allowCoAuthors时Co-Authored-By总是被禁止
+func (p *Plugin) Configure(data json.RawMessage) error {
+	var config Config
+	if err := types.DecodeConfig(data, &config); err != nil {
+		return err
+	}
+
+	if config.Pattern != nil {
+		if err := config.Pattern.compile("pattern"); err != nil {
+			return err
+		}
+	}
+	forbid := make([]*Rule, 0, len(config.Forbid)+1)
+	if !config.AllowCoAuthors {
+		forbid = append(forbid, coAuthorRule)
+	}
+	for i := range config.Forbid {
+		rule := &config.Forbid[i]
+		if err := rule.compile(fmt.Sprintf("forbid[%d]", i)); err != nil {
+			return err
+		}
+		forbid = append(forbid, rule)
+	}
+	for i, t := range config.Types {
+		if t == "" || strings.ContainsAny(t, "(): \t") {
+			return fmt.Errorf("types[%d]: invalid type %q", i, t)
+		}
+	}
+	maxSubjectLength := defaultMaxSubjectLength
+	if config.MaxSubjectLength != nil {
+		if *config.MaxSubjectLength < 0 {
+			return fmt.Errorf("maxSubjectLength: must not be negative")
+		}
+		maxSubjectLength = *config.MaxSubjectLength
+	}
+
+	p.conventional = config.Pattern == nil
+	if config.Conventional != nil {
+		p.conventional = *config.Conventional
+	}
+	p.types = defaultTypes
+	if config.Types != nil {
+		p.types = config.Types
+	}
+	p.pattern = config.Pattern
+	p.maxSubjectLength = maxSubjectLength
+	p.forbid = forbid
+	p.allowNoVerify = config.AllowNoVerify
+	p.allowAmendPushed = config.AllowAmendPushed
+	return nil
+}
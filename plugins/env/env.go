@@ -3,63 +3,337 @@ package main
 import (
 	"claude-hooks/types"
 	"fmt"
-	"regexp"
-)
-
-var (
-	// Patterns for example env files (allowed)
-	exampleFilePattern1 = regexp.MustCompile(`(?i)\.env\.(example|sample|template|dist)$`)
-	exampleFilePattern2 = regexp.MustCompile(`(?i)\.env\..*\.(example|sample|template|dist)$`)
-
-	// Patterns for actual env files (blocked)
-	envFilePattern1 = regexp.MustCompile(`(?i)\.env$`)        // .env
-	envFilePattern2 = regexp.MustCompile(`(?i)\.env\.[^.]+$`) // .env.local, .env.production, etc.
+	"strings"
 )
 
 type EnvPlugin struct {
 	types.UnimplementedPlugin
+	// 允许访问的文件，优先于deny
+	allow []*Rule
+	// 受保护的文件
+	deny []*Rule
+	// 是否按inode识别受保护文件的硬链接
+	matchInodes bool
+	// 按项目目录缓存的受保护文件，matchInodes为true时使用
+	secrets secretCache
 }
 
 func New() types.IPlugin {
-	return &EnvPlugin{}
+	return &EnvPlugin{allow: builtinAllow(), deny: builtinDeny()}
 }
 
 func (e *EnvPlugin) GetMetadata() types.PluginMetadata {
 	return types.PluginMetadata{
-		Description: "阻止读取.env文件",
-		Matcher: struct {
-			PreToolUse  string
-			PostToolUse string
-		}{
-			"Read|Write|Edit|MultiEdit",
-			"",
+		Description: "阻止读取.env等受保护的文件",
+		Matchers: []types.Matcher{
+			types.OnPreToolUse(types.Tools("Read", "Write", "Edit", "MultiEdit", "Bash", "Grep", "Glob")),
 		},
+		APIVersion:   types.APIVersion,
+		ConfigSchema: configSchema,
 	}
 }
 
+// finding 对受保护文件的一次访问
+type finding struct {
+	rule *Rule
+	// 被访问的对象，如 "File: /project/.env"
+	subject string
+}
+
 func (e *EnvPlugin) PreToolUse(arg types.ToolInput) (*types.PreToolUseOutput, error) {
 	var ret types.PreToolUseOutput
-	filePath := arg.GetFilePath()
-	if filePath == "" {
+
+	ck := &checker{EnvPlugin: e, cwd: arg.Cwd, projectDir: arg.ProjectDir()}
+	f, err := ck.check(arg)
+	if err != nil || f == nil {
+		return nil, err
+	}
+
+	reason := f.rule.reason(f.subject)
+	if f.rule.Action == ActionAsk {
+		return ret.Ask(reason), nil
+	}
+	return ret.Approve(false, reason), nil
+}
+
+// checker 检查一次工具调用中出现的所有路径，cwd用于解析Bash命令和Grep中的相对路径
+type checker struct {
+	*EnvPlugin
+	cwd        string
+	projectDir string
+}
+
+// check 返回工具调用对受保护文件的访问，没有访问时返回nil
+func (ck *checker) check(arg types.ToolInput) (*finding, error) {
+	switch arg.ToolName {
+	case "Bash":
+		input, err := arg.AsBash()
+		if err != nil {
+			return nil, err
+		}
+		return ck.checkCommand(input.Command), nil
+
+	case "Grep":
+		input, err := arg.AsGrep()
+		if err != nil {
+			return nil, err
+		}
+		if rule, note := ck.matchFile(input.Path); rule != nil {
+			return &finding{rule, "Path: " + input.Path + note}, nil
+		}
+		if rule := ck.matchGlob(input.Glob); rule != nil {
+			return &finding{rule, "Glob: " + input.Glob}, nil
+		}
+		return nil, nil
+
+	case "Glob":
+		input, err := arg.AsGlob()
+		if err != nil {
+			return nil, err
+		}
+		if rule, note := ck.matchFile(input.Path); rule != nil {
+			return &finding{rule, "Path: " + input.Path + note}, nil
+		}
+		if rule := ck.matchGlob(input.Pattern); rule != nil {
+			return &finding{rule, "Pattern: " + input.Pattern}, nil
+		}
 		return nil, nil
 	}
 
-	// Check if this is an example env file (allowed)
-	isExampleFile := exampleFilePattern1.MatchString(filePath) ||
-		exampleFilePattern2.MatchString(filePath)
+	filePath := arg.GetFilePath()
+	if rule, note := ck.matchFile(filePath); rule != nil {
+		return &finding{rule, "File: " + filePath + note}, nil
+	}
+	return nil, nil
+}
 
-	if isExampleFile {
-		return nil, nil // Allow example env files
+// matchToken 返回命令中的单词引用的受保护文件的规则，以及说明路径如何解析到受保护文件的附注
+func (ck *checker) matchToken(token string) (*Rule, string) {
+	// --env-file=.env、FOO=.env 等形式只检查值
+	if i := strings.LastIndexByte(token, '='); i >= 0 {
+		token = token[i+1:]
 	}
+	// 包含空白的单词是文本而不是路径，如提交信息
+	if token == "" || strings.ContainsAny(token, " \t\n") {
+		return nil, ""
+	}
+	if strings.ContainsAny(token, "*?[{") {
+		return ck.matchGlob(token), ""
+	}
+	return ck.matchFile(token)
+}
 
-	// Check if this is a .env file or variant (blocked)
-	isEnvFile := envFilePattern1.MatchString(filePath) ||
-		envFilePattern2.MatchString(filePath)
+// dataCommands 参数是文本而不是文件的命令，输出通过管道传给其他命令（如xargs）时仍需检查
+var dataCommands = map[string]bool{"echo": true, "printf": true}
 
-	if isEnvFile {
-		msg := fmt.Sprintf("Access to .env files is not allowed. File: %s", filePath)
-		return ret.Approve(false, msg), nil
+// checkCommand 返回Bash命令对受保护文件的访问
+func (ck *checker) checkCommand(command string) *finding {
+	commands, err := types.ParseShell(command)
+	if err != nil {
+		// 无法解析时按空白拆分检查
+		for _, field := range strings.Fields(command) {
+			if rule, note := ck.matchToken(strings.Trim(field, "\"'`$();|&<>")); rule != nil {
+				return tokenFinding(rule, field+note, "")
+			}
+		}
+		return nil
 	}
 
-	return nil, nil
+	for _, c := range commands {
+		if f := ck.checkSimpleCommand(c); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// tokenFinding 返回命令中的单词访问受保护文件的finding，hint为可选的提示
+func tokenFinding(rule *Rule, token, hint string) *finding {
+	subject := "Command references: " + token
+	if hint != "" {
+		subject += " (" + hint + ")"
+	}
+	return &finding{rule, subject}
+}
+
+func (ck *checker) checkSimpleCommand(c types.ShellCommand) *finding {
+	for _, r := range c.Redirects {
+		if !r.IsFile() {
+			continue
+		}
+		if rule, note := ck.matchToken(r.Target); rule != nil {
+			return tokenFinding(rule, r.Target+note, "")
+		}
+	}
+	for _, assignment := range c.Assignments {
+		if rule, note := ck.matchToken(assignment); rule != nil {
+			return tokenFinding(rule, assignment+note, "")
+		}
+	}
+
+	inner := c.Unwrap()
+	if len(inner.Args) == 0 {
+		return nil
+	}
+
+	switch inner.Name() {
+	case "grep", "egrep", "fgrep", "rg":
+		return ck.checkSearch(inner)
+	}
+	if dataCommands[inner.Name()] && !c.Piped() {
+		return nil
+	}
+
+	for _, arg := range c.Args {
+		if rule, note := ck.matchToken(arg); rule != nil {
+			return tokenFinding(rule, arg+note, "")
+		}
+	}
+	return nil
+}
+
+// grepValueOptions 需要参数值的grep/rg短选项
+var grepValueOptions = map[string]bool{
+	"-e": true, "-f": true, "-m": true, "-A": true, "-B": true, "-C": true,
+	"-d": true, "-D": true, "-g": true, "-t": true, "-T": true, "-j": true,
+}
+
+// checkSearch 检查grep/rg命令：搜索的路径不能是受保护的文件，
+// 递归搜索当前目录或上级目录时必须排除受保护的文件
+func (ck *checker) checkSearch(c types.ShellCommand) *finding {
+	name := c.Name()
+	var (
+		paths        []string
+		hasPattern   bool
+		endOfOptions bool
+		recursive    = name == "rg"
+		hidden       = name != "rg"
+		excludes     []string
+		recurseArg   string
+	)
+
+	args := c.Args[1:]
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		value := ""
+		switch {
+		case arg == "--" && !endOfOptions:
+			endOfOptions = true
+			continue
+		case endOfOptions || arg == "-" || !strings.HasPrefix(arg, "-"):
+			if !hasPattern {
+				// 第一个位置参数是搜索模式
+				hasPattern = true
+				continue
+			}
+			paths = append(paths, arg)
+			continue
+		case strings.HasPrefix(arg, "--"):
+			option, v, found := strings.Cut(arg, "=")
+			if !found && (option == "--regexp" || option == "--file" || option == "--exclude" || option == "--glob" || option == "--iglob") && i+1 < len(args) {
+				i++
+				v = args[i]
+			}
+			value = v
+			switch option {
+			case "--recursive", "--dereference-recursive":
+				recursive, recurseArg = true, arg
+			case "--hidden":
+				hidden, recurseArg = true, arg
+			case "--regexp":
+				hasPattern = true
+				continue
+			case "--file":
+				hasPattern = true
+			case "--exclude", "--exclude-dir":
+				excludes = append(excludes, value)
+				continue
+			case "--glob", "--iglob":
+				if excluded, ok := strings.CutPrefix(value, "!"); ok {
+					excludes = append(excludes, excluded)
+					continue
+				}
+			}
+		default:
+			flags := arg[1:]
+		flagLoop:
+			for j, flag := range flags {
+				switch {
+				case (flag == 'r' || flag == 'R') && name != "rg":
+					recursive, recurseArg = true, arg
+				case flag == 'u' && name == "rg" && strings.Count(flags, "u") >= 2:
+					hidden, recurseArg = true, arg
+				case flag == '.' && name == "rg":
+					hidden, recurseArg = true, arg
+				}
+				option := "-" + string(flag)
+				if !grepValueOptions[option] {
+					continue
+				}
+				if j+1 < len(flags) {
+					value = flags[j+1:]
+				} else if i+1 < len(args) {
+					i++
+					value = args[i]
+				}
+				switch option {
+				case "-e":
+					hasPattern = true
+					value = ""
+				case "-f":
+					hasPattern = true
+				case "-g":
+					if excluded, ok := strings.CutPrefix(value, "!"); ok {
+						excludes = append(excludes, excluded)
+						value = ""
+					}
+				}
+				// 选项值占用了剩余的字符
+				break flagLoop
+			}
+		}
+		if rule, note := ck.matchToken(value); rule != nil {
+			return tokenFinding(rule, value+note, "")
+		}
+	}
+
+	for _, p := range paths {
+		if rule, note := ck.matchToken(p); rule != nil {
+			return tokenFinding(rule, p+note, "")
+		}
+	}
+
+	if !recursive {
+		return nil
+	}
+	rule := ck.searchedRule(excludes, hidden)
+	if rule == nil {
+		return nil
+	}
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	for _, p := range paths {
+		if !isDirectoryRoot(p) {
+			continue
+		}
+		if recurseArg == "" {
+			recurseArg = name
+		}
+		exclude := fmt.Sprintf("--exclude='%s'", rule.label)
+		if name == "rg" {
+			exclude = fmt.Sprintf("-g '!%s'", rule.label)
+		}
+		return tokenFinding(rule, recurseArg, fmt.Sprintf("recursive search of %s includes %s files; exclude them with %s", p, rule.label, exclude))
+	}
+	return nil
+}
+
+// isDirectoryRoot 判断路径是否为当前目录、上级目录或家目录，这些目录下通常有受保护的文件
+func isDirectoryRoot(p string) bool {
+	switch strings.TrimSuffix(p, "/") {
+	case "", ".", "..", "~", "*", "$HOME", "$PWD":
+		return true
+	}
+	return false
 }
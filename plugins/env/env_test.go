@@ -0,0 +1,184 @@
+package main
+
+import (
+	"claude-hooks/types"
+	"claude-hooks/types/hooktest"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFixtures(t *testing.T) {
+	hooktest.Run(t, New, "testdata")
+}
+
+// testConfig testdata/config中的夹具使用的配置
+const testConfig = `{
+  "deny": [
+    {"glob": "secrets.yaml", "message": "Secrets are managed by sops; decrypt them with sops -d"},
+    {"glob": "*.pem", "action": "ask"},
+    {"regex": "(^|/)id_(rsa|ed25519)$", "message": "SSH keys must not be read"},
+    {"glob": "credentials.json"},
+    {"glob": ".npmrc", "message": "npm tokens must not be read"}
+  ],
+  "allow": [{"glob": ".env.test"}]
+}`
+
+func TestConfiguredFixtures(t *testing.T) {
+	hooktest.RunConfig(t, New, "testdata/config", json.RawMessage(testConfig))
+}
+
+func TestConfigureErrors(t *testing.T) {
+	tests := []struct {
+		config string
+		want   string
+	}{
+		{`{"deny": [{"glob": "*.pem", "action": "warn"}]}`, `deny[0]: invalid action "warn"`},
+		{`{"deny": [{"glob": "a", "regex": "b"}]}`, "deny[0]: glob and regex are mutually exclusive"},
+		{`{"allow": [{}]}`, "allow[0]: glob or regex is required"},
+		{`{"deny": [{"regex": "("}]}`, `deny[0]: invalid regex "("`},
+		{`{"deny": [{"glob": "[a"}]}`, `deny[0]: invalid glob "[a"`},
+		{`{"block": []}`, `unknown field "block"`},
+	}
+
+	for _, tt := range tests {
+		err := New().(*EnvPlugin).Configure(json.RawMessage(tt.config))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Configure(%s) = %v, want %q", tt.config, err, tt.want)
+		}
+	}
+}
+
+func TestConfigureWithoutDefaults(t *testing.T) {
+	plugin := New().(*EnvPlugin)
+	if err := plugin.Configure(json.RawMessage(`{"defaults": false, "deny": [{"glob": "*.key"}]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if plugin.match("/project/.env") != nil {
+		t.Error(".env is protected although defaults are disabled")
+	}
+	if plugin.match("/project/tls.key") == nil {
+		t.Error("tls.key is not protected")
+	}
+}
+
+func TestConfigureProject(t *testing.T) {
+	plugin := New().(*EnvPlugin)
+	config := `{"defaults": false, "deny": [{"glob": "*.key"}], "allow": [{"glob": "**"}, {"glob": ".env.test"}]}`
+	if err := plugin.ConfigureProject(json.RawMessage(config)); err != nil {
+		t.Fatal(err)
+	}
+	// 项目配置不能关闭或豁免内置规则
+	for _, filePath := range []string{"/project/.env", "/project/.env.test"} {
+		if plugin.match(filePath) == nil {
+			t.Errorf("%s is not protected by a project config", filePath)
+		}
+	}
+	if plugin.match("/project/.env.example") != nil {
+		t.Error(".env.example is protected")
+	}
+	// 项目配置的allow规则可以豁免它自己的deny规则
+	if plugin.match("/project/tls.key") != nil {
+		t.Error("tls.key is protected although the project config allows it")
+	}
+
+	if err := plugin.Configure(json.RawMessage(config)); err != nil {
+		t.Fatal(err)
+	}
+	if plugin.match("/project/.env") != nil {
+		t.Error(".env is protected although the user config disables the defaults")
+	}
+}
+
+// bypassProject 创建包含.env的项目目录，以及指向它的符号链接和硬链接
+func bypassProject(t *testing.T) string {
+	t.Helper()
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	write := func(name string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("TOKEN=secret\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".env")
+	write("secrets.yaml")
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for link, target := range map[string]string{"config": ".env", "chain": "config", "sub/settings": "../secrets.yaml"} {
+		if err := os.Symlink(target, filepath.Join(dir, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Link(filepath.Join(dir, ".env"), filepath.Join(dir, "notes.txt")); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CLAUDE_PROJECT_DIR", dir)
+	t.Setenv("HOME", filepath.Join(dir, "sub"))
+	return dir
+}
+
+func TestPathBypasses(t *testing.T) {
+	dir := bypassProject(t)
+	env := filepath.Join(dir, ".env")
+
+	tests := []struct {
+		name   string
+		config string
+		tool   string
+		input  map[string]any
+		// 期望阻止原因中包含的内容，为空表示允许
+		want string
+	}{
+		{"symlink", "", "Read", map[string]any{"file_path": "config"}, "File: config (resolves to " + env + ")"},
+		{"symlink chain", "", "Read", map[string]any{"file_path": filepath.Join(dir, "chain")}, "(resolves to " + env + ")"},
+		{"symlink in bash", "", "Bash", map[string]any{"command": "cat ./config | head"}, "Command references: ./config (resolves to " + env + ")"},
+		{"symlink as grep path", "", "Grep", map[string]any{"pattern": "TOKEN", "path": "chain"}, "Path: chain (resolves to " + env + ")"},
+		{"trailing dot", "", "Read", map[string]any{"file_path": "./.env/."}, "(resolves to " + env + ")"},
+		{"dot dot and trailing slash", "", "Edit", map[string]any{"file_path": "sub/../.env/"}, "(resolves to " + env + ")"},
+		{"home relative", "", "Read", map[string]any{"file_path": "~/../config"}, "(resolves to " + env + ")"},
+		{"upper case", "", "Read", map[string]any{"file_path": "/project/.ENV"}, "File: /project/.ENV"},
+		{"upper case glob rule", `{"deny": [{"glob": "secrets.yaml"}]}`, "Read", map[string]any{"file_path": "/project/SECRETS.YAML"}, "File: /project/SECRETS.YAML"},
+		{"upper case bash glob", `{"deny": [{"glob": "secrets.yaml"}]}`, "Bash", map[string]any{"command": "cat Secrets.*"}, "Command references: Secrets.*"},
+		{"symlink to configured file", `{"deny": [{"glob": "secrets.yaml"}]}`, "Read", map[string]any{"file_path": "sub/settings"}, "(resolves to " + filepath.Join(dir, "secrets.yaml") + ")"},
+		{"hard link without inode matching", "", "Read", map[string]any{"file_path": "notes.txt"}, ""},
+		{"hard link", `{"matchInodes": true}`, "Read", map[string]any{"file_path": "notes.txt"}, "File: notes.txt (same file as " + env + ")"},
+		{"hard link in bash", `{"matchInodes": true}`, "Bash", map[string]any{"command": "cp notes.txt /tmp/x"}, "(same file as " + env + ")"},
+		{"unrelated file with inode matching", `{"matchInodes": true}`, "Read", map[string]any{"file_path": "sub"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := New().(*EnvPlugin)
+			if tt.config != "" {
+				if err := plugin.Configure(json.RawMessage(tt.config)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			arg := types.ToolInput{ToolName: tt.tool, ToolInput: tt.input}
+			arg.Cwd = dir
+			output, err := plugin.PreToolUse(arg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.want == "" {
+				if output != nil {
+					t.Errorf("blocked: %s", *output.Reason)
+				}
+				return
+			}
+			if output == nil || output.Reason == nil {
+				t.Fatalf("not blocked, want reason containing %q", tt.want)
+			}
+			if !strings.Contains(*output.Reason, tt.want) {
+				t.Errorf("reason = %q, want it to contain %q", *output.Reason, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"claude-hooks/types"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// maxSecretScan 按inode匹配时扫描项目目录的最大条目数
+const maxSecretScan = 20000
+
+// skipDirs 扫描项目目录时跳过的目录
+var skipDirs = map[string]bool{".git": true, "node_modules": true, "vendor": true}
+
+// matchFile 返回路径访问的受保护文件的规则，以及说明路径如何解析到受保护文件的附注
+//
+// 依次检查原始路径、基于工作目录清理后的绝对路径和解析符号链接后的真实路径，
+// 开启matchInodes时还会检查路径是否为项目中受保护文件的硬链接。
+func (ck *checker) matchFile(filePath string) (*Rule, string) {
+	if filePath == "" {
+		return nil, ""
+	}
+	if rule := ck.match(filePath); rule != nil {
+		return rule, ""
+	}
+
+	resolved := ck.resolve(filePath)
+	for _, p := range resolved {
+		if rule := ck.match(p); rule != nil {
+			return rule, " (resolves to " + p + ")"
+		}
+	}
+
+	if ck.matchInodes {
+		real := resolved[len(resolved)-1]
+		if rule, secret := ck.matchInode(real); rule != nil {
+			return rule, " (same file as " + secret + ")"
+		}
+	}
+	return nil, ""
+}
+
+// resolve 返回基于工作目录清理后的绝对路径，以及解析符号链接后的真实路径（与前者不同时）
+func (ck *checker) resolve(filePath string) []string {
+	if rest, ok := strings.CutPrefix(filePath, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			filePath = filepath.Join(home, rest)
+		}
+	}
+	if !filepath.IsAbs(filePath) && ck.cwd != "" {
+		filePath = filepath.Join(ck.cwd, filePath)
+	}
+	clean := filepath.Clean(filePath)
+
+	paths := []string{clean}
+	if real := types.RealPath(clean); real != clean {
+		paths = append(paths, real)
+	}
+	return paths
+}
+
+// secretFile 项目中的受保护文件
+type secretFile struct {
+	path string
+	info fs.FileInfo
+	rule *Rule
+}
+
+// secretCache 按项目目录缓存扫描到的受保护文件
+type secretCache struct {
+	mu    sync.Mutex
+	files map[string][]secretFile
+}
+
+// matchInode 判断路径是否与项目中某个受保护文件是同一个文件（硬链接），返回该文件的规则和路径
+func (ck *checker) matchInode(p string) (*Rule, string) {
+	info, err := os.Stat(p)
+	if err != nil || !info.Mode().IsRegular() {
+		return nil, ""
+	}
+	for _, secret := range ck.secretFiles() {
+		if os.SameFile(info, secret.info) {
+			return secret.rule, secret.path
+		}
+	}
+	return nil, ""
+}
+
+// secretFiles 返回项目目录中的受保护文件，结果按项目目录缓存
+func (ck *checker) secretFiles() []secretFile {
+	if ck.projectDir == "" {
+		return nil
+	}
+
+	ck.secrets.mu.Lock()
+	defer ck.secrets.mu.Unlock()
+	if files, ok := ck.secrets.files[ck.projectDir]; ok {
+		return files
+	}
+
+	var files []secretFile
+	scanned := 0
+	_ = filepath.WalkDir(ck.projectDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if scanned++; scanned > maxSecretScan {
+			return filepath.SkipAll
+		}
+		if d.IsDir() {
+			if skipDirs[d.Name()] && p != ck.projectDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rule := ck.match(p)
+		if rule == nil {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			files = append(files, secretFile{path: p, info: info, rule: rule})
+		}
+		return nil
+	})
+
+	if ck.secrets.files == nil {
+		ck.secrets.files = make(map[string][]secretFile)
+	}
+	ck.secrets.files[ck.projectDir] = files
+	return files
+}
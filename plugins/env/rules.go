@@ -0,0 +1,306 @@
+package main
+
+import (
+	"claude-hooks/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Config env插件的配置，从 .claude/plugins/env.json 读取
+//
+//	{
+//	  "deny": [
+//	    {"glob": "*.pem", "message": "Private keys must not be read", "action": "ask"},
+//	    {"regex": "(^|/)secrets\\.ya?ml$"}
+//	  ],
+//	  "allow": [{"glob": ".env.test"}]
+//	}
+type Config struct {
+	// 为false时不使用内置的.env规则，默认为true
+	Defaults *bool `json:"defaults,omitempty"`
+	// 受保护的文件，按顺序匹配，优先于内置规则
+	Deny []Rule `json:"deny,omitempty"`
+	// 允许访问的文件，优先于所有deny规则
+	Allow []Rule `json:"allow,omitempty"`
+	// 扫描项目目录中的受保护文件，按inode识别它们的硬链接
+	MatchInodes bool `json:"matchInodes,omitempty"`
+}
+
+// Rule 文件规则，Glob和Regex二选一
+type Rule struct {
+	// 文件glob，支持**，不区分大小写；不含/时只匹配文件名
+	Glob string `json:"glob,omitempty"`
+	// 路径的正则，在路径中搜索
+	Regex string `json:"regex,omitempty"`
+	// 阻止时的原因，为空时使用默认原因
+	Message string `json:"message,omitempty"`
+	// block（默认）或 ask，只对deny规则有效
+	Action string `json:"action,omitempty"`
+
+	re *regexp.Regexp
+	// 规则匹配的样例文件名，用于判断命令中的通配符能否匹配到受保护的文件
+	samples []string
+	// 在提示中代表该规则的glob
+	label string
+	// 内置规则
+	builtin bool
+	// 来自项目配置的allow规则，不能豁免内置的deny规则
+	project bool
+}
+
+const (
+	ActionBlock = "block"
+	ActionAsk   = "ask"
+)
+
+// defaultMessage 用户在env.json中添加的deny规则没有写message时，提示中说明文件受保护
+const defaultMessage = "Access to protected files is not allowed"
+
+// configSchema env.json的格式，deny和allow共用$defs中的rule
+var configSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "defaults": {"type": "boolean", "description": "Keep the built-in .env rules (default true)"},
+    "deny": {"type": "array", "items": {"$ref": "#/$defs/rule"}},
+    "allow": {"type": "array", "items": {"$ref": "#/$defs/rule"}},
+    "matchInodes": {"type": "boolean", "description": "Also block hard links to protected files in the project"}
+  },
+  "additionalProperties": false,
+  "$defs": {
+    "rule": {
+      "type": "object",
+      "properties": {
+        "glob": {"type": "string"},
+        "regex": {"type": "string"},
+        "message": {"type": "string"},
+        "action": {"enum": ["block", "ask"]}
+      },
+      "additionalProperties": false
+    }
+  }
+}`)
+
+var (
+	// 示例env文件，如 .env.example、.env.local.sample
+	exampleFilePattern1 = regexp.MustCompile(`(?i)\.env\.(example|sample|template|dist)$`)
+	exampleFilePattern2 = regexp.MustCompile(`(?i)\.env\..*\.(example|sample|template|dist)$`)
+
+	// 实际的env文件：.env，以及 .env.local、.env.production 等
+	envFilePattern1 = regexp.MustCompile(`(?i)\.env$`)
+	envFilePattern2 = regexp.MustCompile(`(?i)\.env\.[^.]+$`)
+)
+
+// builtinAllow 内置的允许规则：示例env文件
+func builtinAllow() []*Rule {
+	return []*Rule{
+		{re: exampleFilePattern1},
+		{re: exampleFilePattern2},
+	}
+}
+
+// builtinDeny 内置的阻止规则：.env文件
+func builtinDeny() []*Rule {
+	const message = "Access to .env files is not allowed"
+	return []*Rule{
+		{re: envFilePattern1, Message: message, label: ".env*", samples: []string{".env"}, builtin: true},
+		{re: envFilePattern2, Message: message, label: ".env*", samples: []string{".env.local", ".env.production"}, builtin: true},
+	}
+}
+
+// compile 检查glob和regex是否恰好设置了一个，编译regex，并记录glob的样例文件名供匹配命令中的通配符
+func (r *Rule) compile() error {
+	switch {
+	case r.Glob != "" && r.Regex != "":
+		return errors.New("glob and regex are mutually exclusive")
+	case r.Glob != "":
+		if _, err := path.Match(path.Base(r.Glob), ""); err != nil {
+			return fmt.Errorf("invalid glob %q: %v", r.Glob, err)
+		}
+		r.label = r.Glob
+		r.samples = []string{globSample(r.Glob)}
+	case r.Regex != "":
+		re, err := regexp.Compile(r.Regex)
+		if err != nil {
+			return fmt.Errorf("invalid regex %q: %v", r.Regex, err)
+		}
+		r.re = re
+		r.label = r.Regex
+	default:
+		return errors.New("glob or regex is required")
+	}
+
+	switch r.Action {
+	case "", ActionBlock, ActionAsk:
+	default:
+		return fmt.Errorf("invalid action %q (expected block or ask)", r.Action)
+	}
+	return nil
+}
+
+// globSample 返回glob能匹配的一个文件名，如 *.pem 返回 x.pem
+func globSample(glob string) string {
+	base := strings.ToLower(path.Base(glob))
+	return strings.NewReplacer("*", "x", "?", "x", "[", "", "]", "").Replace(base)
+}
+
+// Match 判断路径是否匹配规则
+// glob不区分大小写，因为在macOS等大小写不敏感的文件系统上 .ENV 和 .env 是同一个文件
+func (r *Rule) Match(filePath string) bool {
+	if r.re != nil {
+		return r.re.MatchString(filePath)
+	}
+	return types.MatchGlob(strings.ToLower(r.Glob), strings.ToLower(filePath))
+}
+
+// reason 返回阻止原因，subject描述被阻止的对象，如 "File: /project/.env"
+func (r *Rule) reason(subject string) string {
+	message := r.Message
+	if message == "" {
+		message = defaultMessage
+	}
+	return fmt.Sprintf("%s. %s", strings.TrimSuffix(message, "."), subject)
+}
+
+// Configure 应用~/.claude/plugins/env.json，用户的allow规则可以豁免内置的.env规则
+func (e *EnvPlugin) Configure(data json.RawMessage) error {
+	return e.configure(data, false)
+}
+
+// ConfigureProject 应用项目中的配置，实现types.ProjectConfigurable
+// Claude可以修改项目中的env.json，所以其中的defaults: false被忽略，allow规则只能豁免项目配置的deny规则，
+// .env文件始终受保护
+func (e *EnvPlugin) ConfigureProject(data json.RawMessage) error {
+	return e.configure(data, true)
+}
+
+func (e *EnvPlugin) configure(data json.RawMessage, project bool) error {
+	var config Config
+	if err := types.DecodeConfig(data, &config); err != nil {
+		return err
+	}
+
+	var allow, deny []*Rule
+	for i := range config.Allow {
+		rule := &config.Allow[i]
+		if err := rule.compile(); err != nil {
+			return fmt.Errorf("allow[%d]: %v", i, err)
+		}
+		rule.project = project
+		allow = append(allow, rule)
+	}
+	for i := range config.Deny {
+		rule := &config.Deny[i]
+		if err := rule.compile(); err != nil {
+			return fmt.Errorf("deny[%d]: %v", i, err)
+		}
+		deny = append(deny, rule)
+	}
+
+	if project || config.Defaults == nil || *config.Defaults {
+		allow = append(allow, builtinAllow()...)
+		deny = append(deny, builtinDeny()...)
+	}
+	e.allow, e.deny = allow, deny
+	e.matchInodes = config.MatchInodes
+	return nil
+}
+
+// match 返回路径匹配的deny规则，路径被允许或不受保护时返回nil
+func (e *EnvPlugin) match(filePath string) *Rule {
+	if filePath == "" {
+		return nil
+	}
+	var allowed []*Rule
+	for _, rule := range e.allow {
+		if rule.Match(filePath) {
+			allowed = append(allowed, rule)
+		}
+	}
+	for _, rule := range e.deny {
+		if rule.Match(filePath) && !exempts(allowed, rule) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// exempts 判断allow规则中是否有可以豁免deny规则的
+func exempts(allow []*Rule, deny *Rule) bool {
+	for _, rule := range allow {
+		if !rule.project || !deny.builtin {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob 返回通配符能匹配到的受保护文件的规则
+// 与shell一致，不以.开头的通配符不匹配隐藏文件
+func (e *EnvPlugin) matchGlob(pattern string) *Rule {
+	if pattern == "" {
+		return nil
+	}
+	for _, alternative := range expandBraces(pattern) {
+		base := strings.ToLower(path.Base(alternative))
+		for _, deny := range e.deny {
+			for _, sample := range deny.samples {
+				if strings.HasPrefix(sample, ".") && !strings.HasPrefix(base, ".") {
+					continue
+				}
+				if matched, _ := path.Match(base, sample); !matched {
+					continue
+				}
+				if rule := e.match(sample); rule != nil {
+					return rule
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// expandBraces 展开 {a,b} 形式的通配符
+func expandBraces(pattern string) []string {
+	start := strings.IndexByte(pattern, '{')
+	if start < 0 {
+		return []string{pattern}
+	}
+	end := strings.IndexByte(pattern[start:], '}')
+	if end < 0 {
+		return []string{pattern}
+	}
+	end += start
+
+	var result []string
+	for _, alternative := range strings.Split(pattern[start+1:end], ",") {
+		result = append(result, expandBraces(pattern[:start]+alternative+pattern[end+1:])...)
+	}
+	return result
+}
+
+// searchedRule 返回递归搜索会读取到的受保护文件的规则
+// excludes为搜索排除的glob，hidden表示搜索是否包括隐藏文件；只使用正则的规则无法判断，不会返回
+func (e *EnvPlugin) searchedRule(excludes []string, hidden bool) *Rule {
+	for _, deny := range e.deny {
+		for _, sample := range deny.samples {
+			if !hidden && strings.HasPrefix(sample, ".") {
+				continue
+			}
+			excluded := false
+			for _, exclude := range excludes {
+				if matched, _ := path.Match(path.Base(exclude), sample); matched {
+					excluded = true
+					break
+				}
+			}
+			if !excluded && e.match(sample) != nil {
+				return deny
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"claude-hooks/types"
+	"fmt"
+	"path/filepath"
+)
+
+type Plugin struct {
+	types.UnimplementedPlugin
+	// 受保护的文件，按顺序匹配
+	rules []Rule
+	// 允许编辑的文件glob
+	allow []string
+	// 是否读取文件头识别生成的文件
+	detectHeader bool
+}
+
+func New() types.IPlugin {
+	return &Plugin{rules: builtinRules(), detectHeader: true}
+}
+
+func (p *Plugin) GetMetadata() types.PluginMetadata {
+	return types.PluginMetadata{
+		Description: "阻止直接编辑生成的文件和vendor目录",
+		Matchers: []types.Matcher{
+			types.OnPreToolUse(types.Tools("Write", "Edit", "MultiEdit", "NotebookEdit")),
+		},
+		APIVersion:   types.APIVersion,
+		ConfigSchema: configSchema,
+	}
+}
+
+func (p *Plugin) PreToolUse(arg types.ToolInput) (*types.PreToolUseOutput, error) {
+	var ret types.PreToolUseOutput
+
+	filePath := arg.GetTargetPath()
+	if filePath == "" {
+		return nil, nil
+	}
+	if !filepath.IsAbs(filePath) && arg.Cwd != "" {
+		filePath = filepath.Join(arg.Cwd, filePath)
+	}
+	rel := types.RelativeToProject(filePath, arg.ProjectDir())
+
+	for _, glob := range p.allow {
+		if types.MatchGlob(glob, rel) {
+			return nil, nil
+		}
+	}
+
+	for _, rule := range p.rules {
+		if !rule.Match(rel) {
+			continue
+		}
+		reason := rule.Reason
+		if reason == "" {
+			reason = defaultReason
+		}
+		hint := "regenerate it"
+		if rule.Command != "" {
+			hint = fmt.Sprintf("run `%s`", rule.Command)
+		}
+		return ret.Approve(false, fmt.Sprintf("Do not edit %s directly: it is %s. Instead, %s.", rel, reason, hint)), nil
+	}
+
+	if !p.detectHeader {
+		return nil, nil
+	}
+	header, generator := generatedHeader(filePath)
+	if header == "" {
+		return nil, nil
+	}
+	by := ""
+	if generator != "" {
+		by = " by " + generator
+	}
+	return ret.Approve(false, fmt.Sprintf("Do not edit %s directly: its \"Code generated ... DO NOT EDIT.\" header marks it as generated%s, "+
+		"so changes will be overwritten. Change the source it is generated from, then %s.", rel, by, regenerateHint(filePath, rel, generator))), nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"claude-hooks/types/hooktest"
+	"encoding/json"
+	"testing"
+)
+
+// testdata/project 中是读取文件头的夹具引用的文件，这些夹具没有cwd，路径相对测试目录
+
+func TestFixtures(t *testing.T) {
+	t.Setenv("CLAUDE_PROJECT_DIR", "")
+	hooktest.Run(t, New, "testdata")
+}
+
+// testConfig testdata/config中的夹具使用的配置
+const testConfig = `{
+  "files": [
+    {"glob": "api/openapi.gen.go", "reason": "generated from api/openapi.yaml", "command": "make openapi"},
+    {"glob": "**/*_templ.go"}
+  ],
+  "allow": ["**/mocks/**"]
+}`
+
+func TestConfiguredFixtures(t *testing.T) {
+	t.Setenv("CLAUDE_PROJECT_DIR", "")
+	hooktest.RunConfig(t, New, "testdata/config", json.RawMessage(testConfig))
+}
+
+func TestConfigureErrors(t *testing.T) {
+	for _, config := range []string{
+		`{"files": [{"command": "make"}]}`,
+		`{"files": [{"glob": "["}]}`,
+		`{"allow": [""]}`,
+		`{"files": [{"glob": "x", "cmd": "make"}]}`,
+	} {
+		if err := New().(*Plugin).Configure(json.RawMessage(config)); err == nil {
+			t.Errorf("Configure(%s) succeeded, want error", config)
+		}
+	}
+}
+
+func TestConfigureWithoutDefaults(t *testing.T) {
+	plugin := New().(*Plugin)
+	if err := plugin.Configure(json.RawMessage(`{"defaults": false, "detectHeader": false}`)); err != nil {
+		t.Fatal(err)
+	}
+	if len(plugin.rules) != 0 || plugin.detectHeader {
+		t.Errorf("rules = %v, detectHeader = %v", plugin.rules, plugin.detectHeader)
+	}
+}
+
+func TestGeneratorName(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{" by protoc-gen-go. ", "protoc-gen-go"},
+		{` by "stringer -type=Status"; `, "stringer"},
+		{" by github.com/99designs/gqlgen, ", "gqlgen"},
+		{" - ", ""},
+		{" ", ""},
+	}
+	for _, tt := range tests {
+		if got := generatorName(tt.header); got != tt.want {
+			t.Errorf("generatorName(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"claude-hooks/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+)
+
+// Config generated插件的配置，从 .claude/plugins/generated.json 读取
+//
+//	{
+//	  "files": [
+//	    {"glob": "api/openapi.gen.go", "reason": "generated from api/openapi.yaml", "command": "make openapi"}
+//	  ],
+//	  "allow": ["internal/mocks/**"]
+//	}
+type Config struct {
+	// 为false时不使用内置的go.sum和vendor规则，默认为true
+	Defaults *bool `json:"defaults,omitempty"`
+	// 为false时不读取文件头识别生成的文件，默认为true
+	DetectHeader *bool `json:"detectHeader,omitempty"`
+	// 受保护的文件，按顺序匹配，优先于内置规则
+	Files []Rule `json:"files,omitempty"`
+	// 允许编辑的文件glob，优先于所有规则和文件头
+	Allow []string `json:"allow,omitempty"`
+}
+
+// Rule 受保护文件的规则
+type Rule struct {
+	// 文件glob，相对项目根目录匹配，支持**；不含/时只匹配文件名
+	Glob string `json:"glob"`
+	// 文件为什么不能直接编辑，接在 "it is" 之后，如 "generated from api/openapi.yaml"
+	Reason string `json:"reason,omitempty"`
+	// 重新生成文件的命令
+	Command string `json:"command,omitempty"`
+}
+
+// defaultReason 规则未设置Reason时的原因
+const defaultReason = "generated by a tool"
+
+// configSchema generated.json的格式
+var configSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "defaults": {"type": "boolean", "description": "Keep the built-in go.sum and vendor rules (default true)"},
+    "detectHeader": {"type": "boolean", "description": "Detect generated files by their 'Code generated ... DO NOT EDIT.' header (default true)"},
+    "files": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "glob": {"type": "string"},
+          "reason": {"type": "string"},
+          "command": {"type": "string"}
+        },
+        "required": ["glob"],
+        "additionalProperties": false
+      }
+    },
+    "allow": {"type": "array", "items": {"type": "string"}, "description": "Globs of files that may be edited anyway"}
+  },
+  "additionalProperties": false
+}`)
+
+// builtinRules 内置规则：由go命令维护的文件
+func builtinRules() []Rule {
+	return []Rule{
+		{Glob: "go.sum", Reason: "maintained by the go command", Command: "go mod tidy"},
+		{Glob: "**/vendor/**", Reason: "a vendored copy of a module dependency", Command: "go mod vendor"},
+	}
+}
+
+// Match 判断相对项目根目录的路径是否匹配规则
+func (r *Rule) Match(rel string) bool {
+	return types.MatchGlob(r.Glob, rel)
+}
+
+func validGlob(glob string) error {
+	if glob == "" {
+		return errors.New("glob is required")
+	}
+	if _, err := path.Match(path.Base(glob), ""); err != nil {
+		return fmt.Errorf("invalid glob %q: %v", glob, err)
+	}
+	return nil
+}
+
+// Configure 把files放在内置规则之前，使项目的规则和原因优先；allow中的文件总是可以编辑
+func (p *Plugin) Configure(data json.RawMessage) error {
+	var config Config
+	if err := types.DecodeConfig(data, &config); err != nil {
+		return err
+	}
+
+	for i, rule := range config.Files {
+		if err := validGlob(rule.Glob); err != nil {
+			return fmt.Errorf("files[%d]: %v", i, err)
+		}
+	}
+	for i, glob := range config.Allow {
+		if err := validGlob(glob); err != nil {
+			return fmt.Errorf("allow[%d]: %v", i, err)
+		}
+	}
+
+	rules := config.Files
+	if config.Defaults == nil || *config.Defaults {
+		rules = append(rules, builtinRules()...)
+	}
+	p.rules, p.allow = rules, config.Allow
+	p.detectHeader = config.DetectHeader == nil || *config.DetectHeader
+	return nil
+}
@@ -0,0 +1,4 @@
+package project
+
+// Files starting with "// Code generated ... DO NOT EDIT." are skipped.
+func isGenerated() bool { return false }
@@ -0,0 +1,3 @@
+package mocks
+
+//go:generate mockgen -source=../store.go -destination=store_mock.go -package=mocks
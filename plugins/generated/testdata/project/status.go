@@ -0,0 +1,4 @@
+package project
+
+//go:generate stringer -type=Status
+type Status int
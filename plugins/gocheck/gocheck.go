@@ -5,6 +5,7 @@ import (
 	"claude-hooks/types"
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
@@ -19,31 +20,28 @@ func New() types.IPlugin {
 func (p *Plugin) GetMetadata() types.PluginMetadata {
 	return types.PluginMetadata{
 		Description: "在编辑完go文件后自动进行语法检查",
-		Matcher: struct {
-			PreToolUse  string
-			PostToolUse string
-		}{
-			PostToolUse: "Write|Edit|MultiEdit",
+		Matchers: []types.Matcher{
+			types.OnPostToolUse(types.Tools("Write", "Edit", "MultiEdit")).WithFiles("**/*.go"),
 		},
+		APIVersion: types.APIVersion,
+		Requires:   []string{"gopls"},
 	}
 }
 
 func (p *Plugin) PostToolUse(arg types.PostToolUseInput) (*types.PostToolUseOutput, error) {
 	var ret types.PostToolUseOutput
-	filePath := arg.ToolInput.GetFilePath()
-	if filePath == "" {
-		return nil, nil
-	}
-	// 只处理Go文件
-	if !strings.HasSuffix(filePath, ".go") {
-		return nil, nil
-	}
-	msg, err := execCommand("gopls", "check", filePath)
-	if err != nil {
-		return nil, err
-	}
-	if len(msg) > 0 {
-		return ret.Block(msg), nil
+	// 只处理实际修改成功的文件，匹配器已限定为Go文件
+	for _, filePath := range arg.AffectedFiles() {
+		if !filepath.IsAbs(filePath) && arg.Cwd != "" {
+			filePath = filepath.Join(arg.Cwd, filePath)
+		}
+		msg, err := execCommand("gopls", "check", filePath)
+		if err != nil {
+			return nil, err
+		}
+		if len(msg) > 0 {
+			return ret.Block(msg), nil
+		}
 	}
 	return nil, nil
 }
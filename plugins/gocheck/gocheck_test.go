@@ -0,0 +1,35 @@
+package main
+
+import (
+	"claude-hooks/types"
+	"claude-hooks/types/hooktest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFixtures(t *testing.T) {
+	hooktest.Run(t, New, "testdata")
+}
+
+func TestRelativePath(t *testing.T) {
+	// 用输出参数的脚本代替gopls，检查传给gopls的路径
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "gopls"), []byte("#!/bin/sh\necho \"$@\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+
+	var arg types.PostToolUseInput
+	arg.Cwd = "/project"
+	arg.ToolName = "Write"
+	arg.ToolInput.ToolInput = map[string]any{"file_path": "cmd/main.go"}
+	arg.ToolResponse = map[string]any{"filePath": "cmd/main.go", "success": true}
+	output, err := New().(*Plugin).PostToolUse(arg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output == nil || output.Reason == nil || *output.Reason != "check /project/cmd/main.go\n" {
+		t.Errorf("output = %+v, want gopls run on /project/cmd/main.go", output)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// diffContext 每个修改前后保留的上下文行数
+	diffContext = 3
+	// maxDiffCells 逐行比较的最大规模，超过时整段按删除再插入处理
+	maxDiffCells = 1 << 22
+	// maxDiffLines 输出的最大行数，超过时截断
+	maxDiffLines = 200
+	// truncated 截断时代替剩余内容的说明
+	truncated = "... (diff truncated)\n"
+)
+
+// diffOp 差异中的一行：' '未修改，'-'删除，'+'插入
+type diffOp struct {
+	kind byte
+	text string
+	// 该行之前a和b中已经过的行数
+	aPos, bPos int
+}
+
+// unifiedDiff 返回从a到b的unified diff，name为文件名，内容相同时返回空字符串
+func unifiedDiff(name, a, b string) string {
+	if a == b {
+		return ""
+	}
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s (formatted)\n", name, name)
+	lines := 2
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		// 相邻修改之间的未修改行不超过2*diffContext时合并到同一个hunk
+		start, end := max(i-diffContext, 0), i+1
+		for j := i; j < len(ops) && j-end < 2*diffContext; j++ {
+			if ops[j].kind != ' ' {
+				end = j + 1
+			}
+		}
+		stop := min(end+diffContext, len(ops))
+
+		var aCount, bCount int
+		for _, op := range ops[start:stop] {
+			if op.kind != '+' {
+				aCount++
+			}
+			if op.kind != '-' {
+				bCount++
+			}
+		}
+		if lines >= maxDiffLines {
+			sb.WriteString(truncated)
+			return sb.String()
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", ops[start].aPos+1, aCount, ops[start].bPos+1, bCount)
+		lines++
+		for _, op := range ops[start:stop] {
+			if lines >= maxDiffLines {
+				sb.WriteString(truncated)
+				return sb.String()
+			}
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.text)
+			lines++
+			if !strings.HasSuffix(op.text, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+				lines++
+			}
+		}
+		i = stop
+	}
+	return sb.String()
+}
+
+// splitLines 按行分割，每行保留换行符
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines 用最长公共子序列比较两组行，返回逐行的操作
+func diffLines(a, b []string) []diffOp {
+	// 跳过相同的开头和结尾，格式化通常只修改少数几行
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	aPos, bPos := 0, 0
+	emit := func(kind byte, text string) {
+		ops = append(ops, diffOp{kind: kind, text: text, aPos: aPos, bPos: bPos})
+		if kind != '+' {
+			aPos++
+		}
+		if kind != '-' {
+			bPos++
+		}
+	}
+
+	for _, line := range a[:prefix] {
+		emit(' ', line)
+	}
+	am, bm := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(am)*len(bm) > maxDiffCells {
+		for _, line := range am {
+			emit('-', line)
+		}
+		for _, line := range bm {
+			emit('+', line)
+		}
+	} else {
+		// lcs[i][j]为am[i:]和bm[j:]的最长公共子序列长度
+		lcs := make([][]int32, len(am)+1)
+		for i := range lcs {
+			lcs[i] = make([]int32, len(bm)+1)
+		}
+		for i := len(am) - 1; i >= 0; i-- {
+			for j := len(bm) - 1; j >= 0; j-- {
+				if am[i] == bm[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(am) || j < len(bm) {
+			switch {
+			case i < len(am) && j < len(bm) && am[i] == bm[j]:
+				emit(' ', am[i])
+				i++
+				j++
+			case j == len(bm) || (i < len(am) && lcs[i+1][j] >= lcs[i][j+1]):
+				emit('-', am[i])
+				i++
+			default:
+				emit('+', bm[j])
+				j++
+			}
+		}
+	}
+	for _, line := range a[len(a)-suffix:] {
+		emit(' ', line)
+	}
+	return ops
+}
@@ -3,13 +3,36 @@ package main
 import (
 	"bytes"
 	"claude-hooks/types"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"go/format"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
+// Config gofmt插件的配置，从 .claude/plugins/gofmt.json 读取
+//
+//	{"goimports": true}
+type Config struct {
+	// 格式化后再用goimports整理import，需要安装goimports
+	Goimports bool `json:"goimports,omitempty"`
+}
+
+// configSchema gofmt.json的格式
+var configSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "goimports": {"type": "boolean", "description": "Also run goimports to add and remove imports (requires goimports on PATH)"}
+  },
+  "additionalProperties": false
+}`)
+
 type Plugin struct {
 	types.UnimplementedPlugin
+	goimports bool
 }
 
 func New() types.IPlugin {
@@ -19,51 +42,95 @@ func New() types.IPlugin {
 func (p *Plugin) GetMetadata() types.PluginMetadata {
 	return types.PluginMetadata{
 		Description: "在编辑完go文件后自动进行格式",
-		Matcher: struct {
-			PreToolUse  string
-			PostToolUse string
-		}{
-			PostToolUse: "Write|Edit|MultiEdit",
+		Matchers: []types.Matcher{
+			types.OnPostToolUse(types.Tools("Write", "Edit", "MultiEdit")).WithFiles("**/*.go"),
 		},
+		APIVersion:   types.APIVersion,
+		ConfigSchema: configSchema,
+	}
+}
+
+// Configure 读取是否在gofmt之后运行goimports
+func (p *Plugin) Configure(data json.RawMessage) error {
+	var config Config
+	if err := types.DecodeConfig(data, &config); err != nil {
+		return err
 	}
+	p.goimports = config.Goimports
+	return nil
 }
 
 func (p *Plugin) PostToolUse(arg types.PostToolUseInput) (*types.PostToolUseOutput, error) {
 	var ret types.PostToolUseOutput
-	filePath := arg.ToolInput.GetFilePath()
-	if filePath == "" {
-		return nil, nil
+	// 只处理实际修改成功的文件，匹配器已限定为Go文件
+	reformatted := false
+	for _, filePath := range arg.AffectedFiles() {
+		if !filepath.IsAbs(filePath) && arg.Cwd != "" {
+			filePath = filepath.Join(arg.Cwd, filePath)
+		}
+		context, err := p.formatFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		if context != "" {
+			ret.AddContext(context)
+			reformatted = true
+		}
 	}
-	// 只处理Go文件
-	if !strings.HasSuffix(filePath, ".go") {
+	if !reformatted {
 		return nil, nil
 	}
-	msg, err := execCommand("goimports", "-w", filePath)
+	return &ret, nil
+}
+
+// formatFile 格式化文件，返回告诉Claude文件被如何修改的上下文，文件无需修改时返回空字符串
+func (p *Plugin) formatFile(filePath string) (string, error) {
+	src, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	formatted, err := format.Source(src)
 	if err != nil {
-		return nil, err
+		// 语法错误留给gocheck报告，这里只说明文件没有被格式化
+		return fmt.Sprintf("%s was not formatted because it does not parse: %v", filePath, err), nil
 	}
-	if len(msg) > 0 {
-		return ret.Block(msg), nil
+	tool := "gofmt"
+	if p.goimports {
+		tool = "goimports"
+		if formatted, err = goimports(filePath, formatted); err != nil {
+			return "", err
+		}
+	}
+	if bytes.Equal(src, formatted) {
+		return "", nil
 	}
-	return nil, nil
-}
 
-func execCommand(name string, args ...string) (string, error) {
-	cmd := exec.Command(name, args...)
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filePath, formatted, info.Mode().Perm()); err != nil {
+		return "", err
+	}
+	diff := unifiedDiff(filePath, string(src), string(formatted))
+	return fmt.Sprintf("%s reformatted %s after your edit, so the file on disk differs from what you wrote. "+
+		"Base further edits on the formatted content:\n%s", tool, filePath, diff), nil
+}
 
+// goimports 用goimports处理src，filePath用于按文件所在目录解析import
+func goimports(filePath string, src []byte) ([]byte, error) {
+	cmd := exec.Command("goimports", "-srcdir", filepath.Dir(filePath))
+	cmd.Stdin = bytes.NewReader(src)
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	err := cmd.Run()
-	if stdout.Len() > 0 {
-		return stdout.String(), nil
-	}
-	if stderr.Len() > 0 {
-		return stderr.String(), nil
-	}
-	if err != nil {
-		return "", fmt.Errorf("exec command %s(%s) failed, %w", name, strings.Join(args, " "), err)
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, fmt.Errorf("goimports is not installed; install it with `go install golang.org/x/tools/cmd/goimports@latest` " +
+				"or turn off \"goimports\" in .claude/plugins/gofmt.json")
+		}
+		return nil, fmt.Errorf("exec command goimports(%s) failed, %w: %s", filePath, err, strings.TrimSpace(stderr.String()))
 	}
-	return "", nil
+	return stdout.Bytes(), nil
 }
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"claude-hooks/types"
+	"claude-hooks/types/hooktest"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFixtures(t *testing.T) {
+	hooktest.Run(t, New, "testdata")
+}
+
+// postToolUse 模拟成功写入filePath后的PostToolUse调用
+func postToolUse(t *testing.T, p *Plugin, filePath string) (*types.PostToolUseOutput, error) {
+	t.Helper()
+	var arg types.PostToolUseInput
+	arg.ToolName = "Write"
+	arg.ToolInput.ToolInput = map[string]any{"file_path": filePath}
+	arg.ToolResponse = map[string]any{"filePath": filePath, "success": true}
+	return p.PostToolUse(arg)
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		// 期望格式化后的文件内容，为空表示不修改
+		want string
+		// 期望上下文中包含的内容，为空表示没有上下文
+		context []string
+	}{
+		{
+			name:    "reformatted",
+			src:     "package main\n\nfunc main() {\nx:=1\n_ = x\n}\n",
+			want:    "package main\n\nfunc main() {\n\tx := 1\n\t_ = x\n}\n",
+			context: []string{"gofmt reformatted", "@@ -1,6 +1,6 @@", "-x:=1\n-_ = x\n+\tx := 1\n+\t_ = x\n }\n"},
+		},
+		{
+			name:    "missing trailing newline",
+			src:     "package main",
+			want:    "package main\n",
+			context: []string{"-package main\n\\ No newline at end of file\n+package main\n"},
+		},
+		{
+			name: "already formatted",
+			src:  "package main\n\nfunc main() {}\n",
+		},
+		{
+			name:    "syntax error",
+			src:     "package main\n\nfunc main() {\n",
+			context: []string{"was not formatted because it does not parse"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "main.go")
+			if err := os.WriteFile(filePath, []byte(tt.src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			output, err := postToolUse(t, New().(*Plugin), filePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(filePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := tt.want
+			if want == "" {
+				want = tt.src
+			}
+			if string(data) != want {
+				t.Errorf("file = %q, want %q", data, want)
+			}
+
+			if len(tt.context) == 0 {
+				if output != nil {
+					t.Errorf("output = %+v, want nil", output)
+				}
+				return
+			}
+			if output == nil || output.HookSpecificOutput == nil {
+				t.Fatalf("no additional context, want %q", tt.context)
+			}
+			if output.Decision != nil {
+				t.Errorf("decision = %s, want none", *output.Decision)
+			}
+			for _, s := range tt.context {
+				if !strings.Contains(output.HookSpecificOutput.AdditionalContext, s) {
+					t.Errorf("context = %q, want it to contain %q", output.HookSpecificOutput.AdditionalContext, s)
+				}
+			}
+		})
+	}
+}
+
+func TestGoimportsNotInstalled(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	filePath := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(filePath, []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := New().(*Plugin)
+	if err := p.Configure(json.RawMessage(`{"goimports": true}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := postToolUse(t, p, filePath); err == nil || !strings.Contains(err.Error(), "goimports is not installed") {
+		t.Errorf("err = %v, want goimports is not installed", err)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	var a, b []string
+	for i := 1; i <= 20; i++ {
+		a = append(a, "line\n")
+		b = append(b, "line\n")
+	}
+	a[1], b[1] = "old 2\n", "new 2\n"
+	a[17] = "old 18\n"
+	b = append(b[:17], b[18:]...)
+
+	want := `--- f.go
++++ f.go (formatted)
+@@ -1,5 +1,5 @@
+ line
+-old 2
++new 2
+ line
+ line
+ line
+@@ -15,6 +15,5 @@
+ line
+ line
+ line
+-old 18
+ line
+ line
+`
+	if got := unifiedDiff("f.go", strings.Join(a, ""), strings.Join(b, "")); got != want {
+		t.Errorf("unifiedDiff =\n%s\nwant\n%s", got, want)
+	}
+	if got := unifiedDiff("f.go", "same\n", "same\n"); got != "" {
+		t.Errorf("unifiedDiff of equal content = %q", got)
+	}
+}
+
+func TestUnifiedDiffTruncated(t *testing.T) {
+	// 第一个hunk恰好在maxDiffLines行处结束：2行文件头、1行hunk头、删除的行和diffContext行上下文
+	deleted := maxDiffLines - 3 - diffContext
+	var a, b strings.Builder
+	for i := 0; i < deleted; i++ {
+		fmt.Fprintf(&a, "old %d\n", i)
+	}
+	for i := 0; i < 3*diffContext; i++ {
+		a.WriteString("same\n")
+		b.WriteString("same\n")
+	}
+	a.WriteString("old tail\n")
+	b.WriteString("new tail\n")
+
+	got := unifiedDiff("f.go", a.String(), b.String())
+	if !strings.HasSuffix(got, truncated) || strings.Count(got, "@@ -") != 1 {
+		t.Errorf("unifiedDiff =\n%s\nwant only the first hunk followed by %q", got, truncated)
+	}
+	if lines := strings.Count(got, "\n"); lines != maxDiffLines+1 {
+		t.Errorf("unifiedDiff has %d lines, want %d", lines, maxDiffLines+1)
+	}
+}
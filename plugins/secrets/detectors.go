@@ -0,0 +1,137 @@
+package main
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+// detector 一类密钥的识别规则
+type detector struct {
+	// 标识，用于在配置中禁用
+	id string
+	// 在原因中显示的名称
+	name string
+	re   *regexp.Regexp
+	// 密钥所在的捕获组，0为整个匹配
+	group int
+	// 匹配内容本身不是密钥时为false，如私钥的BEGIN行
+	redact bool
+	// 可选的额外校验
+	validate func(value string) bool
+}
+
+// detectors 内置的识别规则，只包含误报率低的模式
+var detectors = []detector{
+	{
+		id:     "aws-access-key",
+		name:   "AWS access key",
+		re:     regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`),
+		redact: true,
+	},
+	{
+		id:     "aws-secret-key",
+		name:   "AWS secret access key",
+		re:     regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*(?::=|=|:)\s*["']?([A-Za-z0-9/+=]{40})\b`),
+		group:  1,
+		redact: true,
+	},
+	{
+		id:     "github-token",
+		name:   "GitHub token",
+		re:     regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9]{22}_[A-Za-z0-9]{59})\b`),
+		redact: true,
+	},
+	{
+		id:   "private-key",
+		name: "private key",
+		re:   regexp.MustCompile(`-----BEGIN (?:(?:RSA|EC|DSA|OPENSSH|ENCRYPTED|PGP) )?PRIVATE KEY(?: BLOCK)?-----`),
+	},
+	{
+		id:     "jwt",
+		name:   "JSON Web Token",
+		re:     regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`),
+		redact: true,
+	},
+	{
+		id:     "slack-token",
+		name:   "Slack token",
+		re:     regexp.MustCompile(`\bxox[baprs]-[0-9]{10,13}-[0-9A-Za-z-]{10,}`),
+		redact: true,
+	},
+	{
+		id:     "stripe-key",
+		name:   "Stripe live key",
+		re:     regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{24,}\b`),
+		redact: true,
+	},
+	{
+		id:     "google-api-key",
+		name:   "Google API key",
+		re:     regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`),
+		redact: true,
+	},
+	{
+		id:       "generic-secret",
+		name:     "high-entropy secret",
+		re:       regexp.MustCompile(`(?i)[A-Za-z0-9_.-]*(?:secret|token|passw(?:or)?d|api_?key|access_?key|private_?key|credential)[A-Za-z0-9_.-]*["']?\s*(?::=|=|:)\s*["'` + "`" + `]([^"'` + "`" + `\s]{16,})["'` + "`" + `]`),
+		group:    1,
+		redact:   true,
+		validate: highEntropy,
+	},
+}
+
+// minEntropy generic-secret要求的最小香农熵（比特/字符）
+const minEntropy = 3.5
+
+// placeholders 出现在值中时视为示例而不是真实密钥
+var placeholders = []string{"example", "xxxx", "your", "changeme", "placeholder", "dummy", "${", "{{"}
+
+// isPlaceholder 判断值是否为文档中常见的示例值
+func isPlaceholder(value string) bool {
+	lower := strings.ToLower(value)
+	for _, p := range placeholders {
+		if strings.Contains(lower, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// highEntropy 判断值是否像随机生成的密钥：熵足够高，且至少包含两类字符
+func highEntropy(value string) bool {
+	classes := 0
+	for _, class := range []string{"abcdefghijklmnopqrstuvwxyz", "ABCDEFGHIJKLMNOPQRSTUVWXYZ", "0123456789"} {
+		if strings.ContainsAny(value, class) {
+			classes++
+		}
+	}
+	return classes >= 2 && entropy(value) >= minEntropy
+}
+
+// entropy 返回字符串的香农熵（比特/字符）
+func entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// redact 遮盖密钥，只保留前4个字符
+func redact(value string) string {
+	if len(value) <= 8 {
+		return strings.Repeat("*", len(value))
+	}
+	return value[:4] + strings.Repeat("*", min(len(value)-4, 16))
+}
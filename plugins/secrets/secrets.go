@@ -0,0 +1,244 @@
+package main
+
+import (
+	"claude-hooks/types"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// allowAnnotation 行内注释，出现在密钥所在行或上一行时不报告该行
+const allowAnnotation = "claude-plugin:allow-secret"
+
+// maxReported 原因中最多列出的密钥数
+const maxReported = 5
+
+// Config secrets插件的配置，从 .claude/plugins/secrets.json 读取
+//
+//	{
+//	  "allow": ["^sk_live_testfixture"],
+//	  "allowFiles": ["**/testdata/**"],
+//	  "disable": ["jwt"]
+//	}
+type Config struct {
+	// 允许的密钥值的正则，匹配到的值不报告
+	Allow []string `json:"allow,omitempty"`
+	// 不检查的文件glob，相对项目根目录匹配；不含/时只匹配文件名
+	AllowFiles []string `json:"allowFiles,omitempty"`
+	// 禁用的识别规则，如 jwt、generic-secret
+	Disable []string `json:"disable,omitempty"`
+}
+
+var configSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "allow": {"type": "array", "items": {"type": "string"}, "description": "Regexes of secret values to ignore"},
+    "allowFiles": {"type": "array", "items": {"type": "string"}, "description": "Globs of files not to scan"},
+    "disable": {"type": "array", "items": {"type": "string"}, "description": "Detector IDs to turn off"}
+  },
+  "additionalProperties": false
+}`)
+
+type Plugin struct {
+	types.UnimplementedPlugin
+	allow      []*regexp.Regexp
+	allowFiles []string
+	detectors  []detector
+}
+
+func New() types.IPlugin {
+	return &Plugin{detectors: detectors}
+}
+
+func (p *Plugin) GetMetadata() types.PluginMetadata {
+	return types.PluginMetadata{
+		Description: "阻止将密钥写入文件",
+		Matchers: []types.Matcher{
+			types.OnPreToolUse(types.Tools("Write", "Edit", "MultiEdit")),
+		},
+		APIVersion:   types.APIVersion,
+		ConfigSchema: configSchema,
+	}
+}
+
+// Configure 编译允许的密钥值的正则，disable中只能出现已知的识别规则ID
+func (p *Plugin) Configure(data json.RawMessage) error {
+	var config Config
+	if err := types.DecodeConfig(data, &config); err != nil {
+		return err
+	}
+
+	var allow []*regexp.Regexp
+	for i, pattern := range config.Allow {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("allow[%d]: invalid regex %q: %v", i, pattern, err)
+		}
+		allow = append(allow, re)
+	}
+
+	disabled := make(map[string]bool)
+	for _, id := range config.Disable {
+		if !knownDetector(id) {
+			return fmt.Errorf("disable: unknown detector %q", id)
+		}
+		disabled[id] = true
+	}
+	var enabled []detector
+	for _, d := range detectors {
+		if !disabled[d.id] {
+			enabled = append(enabled, d)
+		}
+	}
+
+	p.allow, p.allowFiles, p.detectors = allow, config.AllowFiles, enabled
+	return nil
+}
+
+// ConfigureProject 校验项目中的secrets.json但不应用它，实现types.ProjectConfigurable
+// 其中的每项设置都会减少扫描，而Claude可以修改项目中的文件，如 {"allowFiles": ["**"]} 会关闭整个插件；
+// 例外需要写在~/.claude/plugins/secrets.json中
+func (p *Plugin) ConfigureProject(data json.RawMessage) error {
+	return New().(*Plugin).Configure(data)
+}
+
+func knownDetector(id string) bool {
+	for _, d := range detectors {
+		if d.id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// secret 检测到的密钥
+type secret struct {
+	detector *detector
+	value    string
+	// 位置，如 "line 3" 或 "edit 2, line 1"
+	location string
+}
+
+func (s secret) String() string {
+	value := s.value
+	if s.detector.redact {
+		value = redact(value)
+	}
+	return fmt.Sprintf("%s %s (%s)", s.detector.name, value, s.location)
+}
+
+func (p *Plugin) PreToolUse(arg types.ToolInput) (*types.PreToolUseOutput, error) {
+	var ret types.PreToolUseOutput
+
+	filePath := arg.GetFilePath()
+	if p.fileAllowed(filePath, arg.ProjectDir()) {
+		return nil, nil
+	}
+
+	var found []secret
+	switch arg.ToolName {
+	case "Write":
+		input, err := arg.AsWrite()
+		if err != nil {
+			return nil, err
+		}
+		found = p.scan(input.Content, "", "")
+	case "Edit":
+		input, err := arg.AsEdit()
+		if err != nil {
+			return nil, err
+		}
+		found = p.scan(input.NewString, input.OldString, "")
+	case "MultiEdit":
+		input, err := arg.AsMultiEdit()
+		if err != nil {
+			return nil, err
+		}
+		for i, edit := range input.Edits {
+			found = append(found, p.scan(edit.NewString, edit.OldString, fmt.Sprintf("edit %d, ", i+1))...)
+		}
+	}
+	if len(found) == 0 {
+		return nil, nil
+	}
+
+	return ret.Approve(false, reason(filePath, found)), nil
+}
+
+// fileAllowed 判断文件是否在allowFiles中
+func (p *Plugin) fileAllowed(filePath, projectDir string) bool {
+	if filePath == "" || len(p.allowFiles) == 0 {
+		return false
+	}
+	rel := filePath
+	if projectDir != "" && filepath.IsAbs(filePath) {
+		if r, err := filepath.Rel(projectDir, filePath); err == nil && !strings.HasPrefix(r, "..") {
+			rel = r
+		}
+	}
+	for _, glob := range p.allowFiles {
+		if types.MatchGlob(glob, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// scan 返回text中的密钥，已经出现在existing中的密钥（Edit未改动的部分）不报告
+func (p *Plugin) scan(text, existing, prefix string) []secret {
+	lines := strings.Split(text, "\n")
+	var found []secret
+	for i, line := range lines {
+		if strings.Contains(line, allowAnnotation) || (i > 0 && strings.Contains(lines[i-1], allowAnnotation)) {
+			continue
+		}
+		// 同一个值可能被多个规则匹配，如aws_secret_access_key也符合generic-secret
+		seen := make(map[string]bool)
+		for d := range p.detectors {
+			detector := &p.detectors[d]
+			for _, match := range detector.re.FindAllStringSubmatch(line, -1) {
+				value := match[detector.group]
+				if seen[value] || p.ignored(detector, value) || (existing != "" && strings.Contains(existing, value)) {
+					continue
+				}
+				seen[value] = true
+				found = append(found, secret{
+					detector: detector,
+					value:    value,
+					location: fmt.Sprintf("%sline %d", prefix, i+1),
+				})
+			}
+		}
+	}
+	return found
+}
+
+func (p *Plugin) ignored(d *detector, value string) bool {
+	if isPlaceholder(value) || (d.validate != nil && !d.validate(value)) {
+		return true
+	}
+	for _, re := range p.allow {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// reason 返回阻止原因，密钥已被遮盖
+func reason(filePath string, found []secret) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Possible secret in %s:", filePath)
+	for i, s := range found {
+		if i == maxReported {
+			fmt.Fprintf(&sb, "\n  ... and %d more", len(found)-maxReported)
+			break
+		}
+		fmt.Fprintf(&sb, "\n  - %s", s)
+	}
+	fmt.Fprintf(&sb, "\nLoad secrets from the environment or a secret manager instead of writing them to files. "+
+		"If this is a false positive, add a `%s` comment to the line.", allowAnnotation)
+	return sb.String()
+}
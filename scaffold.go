@@ -0,0 +1,319 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+const (
+	pluginKindSO   = "so"   // 编译为.so，由claude-plugin加载
+	pluginKindExec = "exec" // 编译为独立可执行文件，直接作为hook命令运行
+)
+
+var pluginNamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]*$`)
+
+// scaffoldEvents 支持生成的hook事件及对应的方法签名
+var scaffoldEvents = map[string]string{
+	"PreToolUse":   "PreToolUse(arg types.ToolInput) (*types.PreToolUseOutput, error)",
+	"PostToolUse":  "PostToolUse(arg types.PostToolUseInput) (*types.PostToolUseOutput, error)",
+	"Notification": "Notification(arg types.NotificationInput) (*types.BaseHookOutput, error)",
+	"Stop":         "Stop(arg types.StopInput) (*types.StopOutput, error)",
+	"SubagentStop": "SubagentStop(arg types.SubagentStopInput) (*types.DecisionOutput, error)",
+	"PreCompact":   "PreCompact(arg types.PreCompactInput) (*types.BaseHookOutput, error)",
+	"SessionStart": "SessionStart(arg types.SessionStartInput) (*types.BaseHookOutput, error)",
+}
+
+// scaffoldEventOrder 生成方法和夹具的顺序
+var scaffoldEventOrder = []string{"PreToolUse", "PostToolUse", "Notification", "Stop", "SubagentStop", "PreCompact", "SessionStart"}
+
+type scaffoldConfig struct {
+	Name    string
+	Events  []string
+	Matcher string
+	Kind    string
+	Dir     string
+}
+
+func parseNewArgs(args []string) (*scaffoldConfig, error) {
+	cfg := &scaffoldConfig{
+		Events: []string{"PreToolUse"},
+		Kind:   pluginKindSO,
+		Dir:    "plugins",
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch arg {
+		case "--events", "--matcher", "--kind", "--dir":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s requires a value", arg)
+			}
+			i++
+			switch arg {
+			case "--events":
+				cfg.Events = strings.Split(args[i], ",")
+			case "--matcher":
+				cfg.Matcher = args[i]
+			case "--kind":
+				cfg.Kind = args[i]
+			case "--dir":
+				cfg.Dir = args[i]
+			}
+
+		default:
+			if strings.HasPrefix(arg, "-") {
+				return nil, fmt.Errorf("unknown option: %q", arg)
+			}
+			if cfg.Name != "" {
+				return nil, fmt.Errorf("unexpected argument: %q", arg)
+			}
+			cfg.Name = arg
+		}
+	}
+
+	if cfg.Name == "" {
+		return nil, errors.New("new requires a plugin name")
+	}
+	if !pluginNamePattern.MatchString(cfg.Name) {
+		return nil, fmt.Errorf("invalid plugin name: %q", cfg.Name)
+	}
+	if cfg.Kind != pluginKindSO && cfg.Kind != pluginKindExec {
+		return nil, fmt.Errorf("invalid plugin kind: %q (expected %s or %s)", cfg.Kind, pluginKindSO, pluginKindExec)
+	}
+
+	requested := make(map[string]bool)
+	for _, event := range cfg.Events {
+		event = strings.TrimSpace(event)
+		if _, ok := scaffoldEvents[event]; !ok {
+			return nil, fmt.Errorf("unknown event: %q", event)
+		}
+		requested[event] = true
+	}
+	cfg.Events = cfg.Events[:0]
+	for _, event := range scaffoldEventOrder {
+		if requested[event] {
+			cfg.Events = append(cfg.Events, event)
+		}
+	}
+
+	if cfg.usesMatcher() {
+		if cfg.Matcher == "" {
+			cfg.Matcher = "*"
+		}
+		if _, err := regexp.Compile(cfg.Matcher); cfg.Matcher != "*" && err != nil {
+			return nil, fmt.Errorf("invalid matcher %q: %w", cfg.Matcher, err)
+		}
+	} else if cfg.Matcher != "" {
+		return nil, errors.New("--matcher only applies to PreToolUse and PostToolUse")
+	}
+
+	return cfg, nil
+}
+
+func (c *scaffoldConfig) hasEvent(event string) bool {
+	for _, e := range c.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *scaffoldConfig) usesMatcher() bool {
+	return c.hasEvent("PreToolUse") || c.hasEvent("PostToolUse")
+}
+
+func handleNewCommand(args []string) error {
+	cfg, err := parseNewArgs(args)
+	if err != nil {
+		return err
+	}
+
+	pluginDir := filepath.Join(cfg.Dir, cfg.Name)
+	if _, err := os.Stat(pluginDir); err == nil {
+		return fmt.Errorf("plugin directory already exists: %s", pluginDir)
+	}
+
+	files, err := renderScaffold(cfg)
+	if err != nil {
+		return err
+	}
+
+	for name, content := range files {
+		path := filepath.Join(pluginDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	pkg := filepath.ToSlash(pluginDir)
+	if !filepath.IsAbs(pluginDir) {
+		pkg = "./" + pkg
+	}
+
+	fmt.Printf("✓ 已创建插件 %s\n", pluginDir)
+	fmt.Println()
+	fmt.Println("下一步:")
+	fmt.Printf("  1. 实现 %s/%s.go 中的TODO\n", pluginDir, cfg.Name)
+	fmt.Printf("  2. go test %s -update   # 生成golden文件\n", pkg)
+	if cfg.Kind == pluginKindExec {
+		fmt.Printf("  3. go build -o ~/.claude/hooks/%s %s\n", cfg.Name, pkg)
+		fmt.Printf("  4. 在settings中添加hook命令: ~/.claude/hooks/%s\n", cfg.Name)
+	} else {
+		fmt.Println("  3. make build-plugin")
+		fmt.Printf("  4. claude-plugin %s configure\n", cfg.Name)
+	}
+	return nil
+}
+
+// renderScaffold 生成插件源码、测试和夹具，返回相对插件目录的文件路径到内容的映射
+func renderScaffold(cfg *scaffoldConfig) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+
+	source, err := renderGo(pluginTemplate, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render plugin source: %w", err)
+	}
+	files[cfg.Name+".go"] = source
+
+	test, err := renderGo(pluginTestTemplate, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render plugin test: %w", err)
+	}
+	files[cfg.Name+"_test.go"] = test
+
+	for _, event := range cfg.Events {
+		fixture, err := json.MarshalIndent(scaffoldFixture(cfg, event), "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s fixture: %w", event, err)
+		}
+		files[filepath.Join("testdata", strings.ToLower(event)+".json")] = append(fixture, '\n')
+	}
+
+	return files, nil
+}
+
+func renderGo(tmpl *template.Template, cfg *scaffoldConfig) ([]byte, error) {
+	var buf bytes.Buffer
+	data := map[string]any{
+		"Name":       cfg.Name,
+		"Kind":       cfg.Kind,
+		"Matcher":    cfg.Matcher,
+		"Pre":        cfg.hasEvent("PreToolUse"),
+		"Post":       cfg.hasEvent("PostToolUse"),
+		"Signatures": scaffoldSignatures(cfg),
+	}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+func scaffoldSignatures(cfg *scaffoldConfig) []string {
+	signatures := make([]string, 0, len(cfg.Events))
+	for _, event := range cfg.Events {
+		signatures = append(signatures, scaffoldEvents[event])
+	}
+	return signatures
+}
+
+// scaffoldFixture 为事件生成一个示例输入
+func scaffoldFixture(cfg *scaffoldConfig, event string) map[string]any {
+	fixture := map[string]any{
+		"session_id":      "test-session",
+		"transcript_path": "/tmp/transcript.jsonl",
+		"hook_event_name": event,
+	}
+
+	switch event {
+	case "PreToolUse", "PostToolUse":
+		fixture["tool_name"] = fixtureToolName(cfg.Matcher)
+		fixture["tool_input"] = map[string]any{}
+		if event == "PostToolUse" {
+			fixture["tool_response"] = map[string]any{}
+		}
+	case "Notification":
+		fixture["message"] = "Claude needs your permission to use Bash"
+	case "Stop", "SubagentStop":
+		fixture["stop_hook_active"] = false
+	case "PreCompact":
+		fixture["trigger"] = "manual"
+		fixture["custom_instructions"] = ""
+	case "SessionStart":
+		fixture["source"] = "startup"
+	}
+	return fixture
+}
+
+// fixtureToolName 从matcher中取第一个工具名作为夹具的tool_name
+func fixtureToolName(matcher string) string {
+	name := strings.Split(matcher, "|")[0]
+	if regexp.MustCompile(`^\w+$`).MatchString(name) {
+		return name
+	}
+	return "Read"
+}
+
+var pluginTemplate = template.Must(template.New("plugin").Parse(`package main
+
+import "claude-hooks/types"
+
+type Plugin struct {
+	types.UnimplementedPlugin
+}
+
+func New() types.IPlugin {
+	return &Plugin{}
+}
+{{if eq .Kind "exec"}}
+// main 使插件可以作为独立的hook命令运行
+func main() {
+	types.Serve(New())
+}
+{{end}}
+func (p *Plugin) GetMetadata() types.PluginMetadata {
+	return types.PluginMetadata{
+		Description: "TODO: 描述{{.Name}}插件的功能",
+{{- if or .Pre .Post}}
+		Matchers: []types.Matcher{
+{{- if .Pre}}
+			types.OnPreToolUse({{printf "%q" .Matcher}}),
+{{- end}}
+{{- if .Post}}
+			types.OnPostToolUse({{printf "%q" .Matcher}}),
+{{- end}}
+		},
+{{- end}}
+		APIVersion: types.APIVersion,
+	}
+}
+{{range .Signatures}}
+func (p *Plugin) {{.}} {
+	// TODO: 实现插件逻辑，返回nil表示不做处理
+	return nil, nil
+}
+{{end}}`))
+
+var pluginTestTemplate = template.Must(template.New("test").Parse(`package main
+
+import (
+	"claude-hooks/types/hooktest"
+	"testing"
+)
+
+func TestFixtures(t *testing.T) {
+	hooktest.Run(t, New, "testdata")
+}
+`))
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseNewArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"defaults", []string{"guard"}, ""},
+		{"events and matcher", []string{"guard", "--events", "Stop,PreToolUse", "--matcher", "Bash"}, ""},
+		{"missing name", []string{"--events", "Stop"}, "requires a plugin name"},
+		{"invalid name", []string{"../guard"}, "invalid plugin name"},
+		{"unknown event", []string{"guard", "--events", "PreCommit"}, "unknown event"},
+		{"invalid kind", []string{"guard", "--kind", "wasm"}, "invalid plugin kind"},
+		{"matcher without tool events", []string{"guard", "--events", "Stop", "--matcher", "Bash"}, "--matcher only applies"},
+		{"invalid matcher", []string{"guard", "--matcher", "Bash("}, "invalid matcher"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseNewArgs(tt.args)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderScaffold(t *testing.T) {
+	cfg, err := parseNewArgs([]string{"guard", "--events", "Stop,PreToolUse", "--matcher", "Bash", "--kind", "exec"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := renderScaffold(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"guard.go", "guard_test.go", "testdata/pretooluse.json", "testdata/stop.json"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("missing generated file %s", name)
+		}
+	}
+
+	source := string(files["guard.go"])
+	for _, want := range []string{"func main()", `types.OnPreToolUse("Bash")`, "func (p *Plugin) PreToolUse(", "func (p *Plugin) Stop("} {
+		if !strings.Contains(source, want) {
+			t.Errorf("generated source missing %q", want)
+		}
+	}
+	for _, unwanted := range []string{"OnPostToolUse", "func (p *Plugin) Notification(", "func (p *Plugin) SubagentStop("} {
+		if strings.Contains(source, unwanted) {
+			t.Errorf("generated source unexpectedly contains %q", unwanted)
+		}
+	}
+}
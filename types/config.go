@@ -0,0 +1,107 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Configurable 可配置的插件
+// 找到插件的配置文件时，加载插件后在Initialize之前调用Configure
+type Configurable interface {
+	Configure(config json.RawMessage) error
+}
+
+// ProjectConfigurable 区分配置来源的插件
+// 配置来自项目目录时调用ConfigureProject代替Configure。项目配置在工作区中，Claude可以修改它，
+// 所以插件应只接受其中收紧保护的设置，忽略关闭或放宽保护的设置
+type ProjectConfigurable interface {
+	Configurable
+	ConfigureProject(config json.RawMessage) error
+}
+
+// DecodeConfig 将配置文件的内容解码到v中
+// 未知字段是错误，这样拼错的设置不会被静默忽略，插件在Configure中使用
+func DecodeConfig(data json.RawMessage, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// ConfigPaths 返回插件配置文件的查找路径，按优先级从高到低排列：
+//   - ~/.claude/plugins/<name>.json
+//   - <项目根目录>/.claude/plugins/<name>.json，项目根目录为CLAUDE_PROJECT_DIR或当前目录
+//
+// 项目配置在工作区中，Claude可以修改它，所以用户配置优先，见ProjectConfigurable
+func ConfigPaths(name string) []string {
+	var paths []string
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(homeDir, ".claude", "plugins", configFileName(name)))
+	}
+	if path := projectConfigPath(name); path != "" {
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// configFileName 返回插件的配置文件名，name为插件名或插件文件名
+func configFileName(name string) string {
+	return strings.TrimSuffix(filepath.Base(name), ".so") + ".json"
+}
+
+// projectConfigPath 返回项目中的配置文件路径，项目根目录为CLAUDE_PROJECT_DIR或当前目录
+func projectConfigPath(name string) string {
+	projectDir := os.Getenv("CLAUDE_PROJECT_DIR")
+	if projectDir == "" {
+		projectDir, _ = os.Getwd()
+	}
+	if projectDir == "" {
+		return ""
+	}
+	return filepath.Join(projectDir, ".claude", "plugins", configFileName(name))
+}
+
+// LoadConfig 读取插件的配置文件，返回第一个存在的文件的内容及其路径
+// 没有配置文件时返回nil和空路径
+func LoadConfig(name string) (json.RawMessage, string, error) {
+	for _, path := range ConfigPaths(name) {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, path, fmt.Errorf("failed to read config %s: %v", path, err)
+		}
+		if !json.Valid(data) {
+			return nil, path, fmt.Errorf("invalid config %s: not valid JSON", path)
+		}
+		return json.RawMessage(data), path, nil
+	}
+	return nil, "", nil
+}
+
+// ConfigurePlugin 为实现了Configurable的插件加载并应用配置，name为插件名或插件文件名
+// 配置来自项目目录且插件实现了ProjectConfigurable时调用ConfigureProject
+func ConfigurePlugin(plugin IPlugin, name string) error {
+	configurable, ok := plugin.(Configurable)
+	if !ok {
+		return nil
+	}
+
+	config, path, err := LoadConfig(name)
+	if err != nil || config == nil {
+		return err
+	}
+	configure := configurable.Configure
+	if project, ok := plugin.(ProjectConfigurable); ok && path == projectConfigPath(name) {
+		configure = project.ConfigureProject
+	}
+	if err := configure(config); err != nil {
+		return fmt.Errorf("invalid config %s: %v", path, err)
+	}
+	return nil
+}
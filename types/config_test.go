@@ -0,0 +1,142 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// configurablePlugin 记录收到的配置
+type configurablePlugin struct {
+	UnimplementedPlugin
+	config json.RawMessage
+	err    error
+}
+
+func (p *configurablePlugin) GetMetadata() PluginMetadata {
+	return PluginMetadata{}
+}
+
+func (p *configurablePlugin) Configure(config json.RawMessage) error {
+	p.config = config
+	return p.err
+}
+
+// projectConfigurablePlugin 记录配置是否来自项目目录
+type projectConfigurablePlugin struct {
+	configurablePlugin
+	project bool
+}
+
+func (p *projectConfigurablePlugin) Configure(config json.RawMessage) error {
+	p.project = false
+	return p.configurablePlugin.Configure(config)
+}
+
+func (p *projectConfigurablePlugin) ConfigureProject(config json.RawMessage) error {
+	p.project = true
+	return p.configurablePlugin.Configure(config)
+}
+
+func writeConfig(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, ".claude", "plugins", name+".json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestConfigurePlugin(t *testing.T) {
+	project, home := t.TempDir(), t.TempDir()
+	t.Setenv("CLAUDE_PROJECT_DIR", project)
+	t.Setenv("HOME", home)
+
+	projectConfig := writeConfig(t, project, "guard", `{"level": "project"}`)
+	plugin := &configurablePlugin{}
+	if err := ConfigurePlugin(plugin, "/some/dir/guard.so"); err != nil {
+		t.Fatal(err)
+	}
+	if string(plugin.config) != `{"level": "project"}` {
+		t.Errorf("config = %s, want project config", plugin.config)
+	}
+
+	// 用户配置优先于项目配置，项目中的配置文件不能放宽用户的设置
+	writeConfig(t, home, "guard", `{"level": "user"}`)
+	if err := ConfigurePlugin(plugin, "guard"); err != nil {
+		t.Fatal(err)
+	}
+	if string(plugin.config) != `{"level": "user"}` {
+		t.Errorf("config = %s, want user config", plugin.config)
+	}
+
+	if err := os.Remove(filepath.Join(home, ".claude", "plugins", "guard.json")); err != nil {
+		t.Fatal(err)
+	}
+	plugin.err = errors.New("unknown level")
+	err := ConfigurePlugin(plugin, "guard")
+	if err == nil || !strings.Contains(err.Error(), projectConfig) || !strings.Contains(err.Error(), "unknown level") {
+		t.Errorf("err = %v, want error naming %s", err, projectConfig)
+	}
+}
+
+func TestConfigureProject(t *testing.T) {
+	project, home := t.TempDir(), t.TempDir()
+	t.Setenv("CLAUDE_PROJECT_DIR", project)
+	t.Setenv("HOME", home)
+
+	writeConfig(t, project, "guard", `{"level": "project"}`)
+	plugin := &projectConfigurablePlugin{}
+	if err := ConfigurePlugin(plugin, "guard.so"); err != nil {
+		t.Fatal(err)
+	}
+	if !plugin.project || string(plugin.config) != `{"level": "project"}` {
+		t.Errorf("project = %v, config = %s, want ConfigureProject with project config", plugin.project, plugin.config)
+	}
+
+	writeConfig(t, home, "guard", `{"level": "user"}`)
+	if err := ConfigurePlugin(plugin, "guard.so"); err != nil {
+		t.Fatal(err)
+	}
+	if plugin.project || string(plugin.config) != `{"level": "user"}` {
+		t.Errorf("project = %v, config = %s, want Configure with user config", plugin.project, plugin.config)
+	}
+}
+
+func TestConfigurePluginWithoutConfig(t *testing.T) {
+	t.Setenv("CLAUDE_PROJECT_DIR", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	plugin := &configurablePlugin{}
+	if err := ConfigurePlugin(plugin, "missing"); err != nil || plugin.config != nil {
+		t.Errorf("ConfigurePlugin = %v, config = %s", err, plugin.config)
+	}
+}
+
+func TestLoadConfigInvalidJSON(t *testing.T) {
+	project := t.TempDir()
+	t.Setenv("CLAUDE_PROJECT_DIR", project)
+	writeConfig(t, project, "broken", `{"deny": [`)
+
+	if _, _, err := LoadConfig("broken"); err == nil || !strings.Contains(err.Error(), "not valid JSON") {
+		t.Errorf("err = %v, want invalid JSON error", err)
+	}
+}
+
+func TestDecodeConfig(t *testing.T) {
+	var config struct {
+		Level string `json:"level"`
+	}
+	if err := DecodeConfig(json.RawMessage(`{"level": "strict"}`), &config); err != nil || config.Level != "strict" {
+		t.Errorf("DecodeConfig = %v, config = %+v", err, config)
+	}
+	if err := DecodeConfig(json.RawMessage(`{"levle": "strict"}`), &config); err == nil || !strings.Contains(err.Error(), `unknown field "levle"`) {
+		t.Errorf("err = %v, want unknown field error", err)
+	}
+}
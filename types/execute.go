@@ -0,0 +1,256 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// namedPlugin 带名称的插件，名称用于在结果中标记插件
+type namedPlugin struct {
+	name   string
+	plugin IPlugin
+}
+
+// ExecuteHook 解析hook输入并依次执行插件，返回以退出码、错误和JSON数据表示的结果
+func ExecuteHook(plugins []IPlugin, data []byte) (Result, error) {
+	result, err := RunHook(plugins, data)
+	if err != nil {
+		return Result{}, err
+	}
+	return result.Result(), nil
+}
+
+// RunHook 解析hook输入并依次执行插件，返回聚合后的类型化结果
+// 插件出错或做出阻塞决策时立即停止，后续插件不再执行
+func RunHook(plugins []IPlugin, data []byte) (*HookResult, error) {
+	named := make([]namedPlugin, 0, len(plugins))
+	for _, plugin := range plugins {
+		named = append(named, namedPlugin{name: PluginName(plugin), plugin: plugin})
+	}
+	return runHook(named, data)
+}
+
+func runHook(plugins []namedPlugin, data []byte) (*HookResult, error) {
+	if len(plugins) == 0 {
+		return nil, errors.New("no plugins loaded")
+	}
+
+	envelope, err := DecodeEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+
+	hookType := envelope.HookEventName
+	aggregate := &HookResult{Event: hookType}
+	if _, ok := hookHandlers[hookType]; !ok {
+		aggregate.Err = fmt.Errorf("unknown hook type: %s", hookType)
+		return aggregate, nil
+	}
+
+	for _, p := range plugins {
+		// 跳过不处理该事件的插件
+		if !Implements(p.plugin, hookType) {
+			continue
+		}
+
+		result := ExecutePlugin(envelope, p.plugin)
+		result.Plugin = p.name
+		aggregate.merge(result)
+		if result.Err != nil || result.Blocked() {
+			return aggregate, nil
+		}
+
+		// 后续插件基于修改后的工具输入执行，修改按顺序叠加
+		if result.UpdatedInput != nil {
+			envelope.ToolInput = cloneMap(result.UpdatedInput)
+		}
+	}
+	return aggregate, nil
+}
+
+// ExecutePlugin 执行单个插件，插件不处理该事件或匹配器不匹配时返回Skipped的结果
+func ExecutePlugin(envelope *HookEnvelope, plugin IPlugin) *HookResult {
+	hookType := envelope.HookEventName
+	handler, ok := hookHandlers[hookType]
+	if !ok {
+		return &HookResult{Event: hookType, Err: fmt.Errorf("unknown hook type: %s", hookType)}
+	}
+	if !Implements(plugin, hookType) {
+		return &HookResult{Event: hookType, Skipped: true}
+	}
+
+	start := time.Now()
+	result := handler(envelope, plugin)
+	result.Event = hookType
+	result.Duration = time.Since(start)
+	return result
+}
+
+var hookHandlers = map[string]func(*HookEnvelope, IPlugin) *HookResult{
+	"PreToolUse":   handlePreToolUse,
+	"PostToolUse":  handlePostToolUse,
+	"Notification": handleNotification,
+	"Stop":         handleStop,
+	"SubagentStop": handleSubagentStop,
+	"PreCompact":   handlePreCompact,
+	"SessionStart": handleSessionStart,
+}
+
+// skipped 匹配器不匹配时的结果
+func skipped() *HookResult {
+	return &HookResult{Skipped: true}
+}
+
+// pluginResult 将插件的输出转换为HookResult，插件返回错误时以错误为准
+func pluginResult[T any](output T, err error, convert func(T) *HookResult) *HookResult {
+	if err != nil {
+		return &HookResult{Err: err}
+	}
+	return convert(output)
+}
+
+func handlePreToolUse(envelope *HookEnvelope, plugin IPlugin) *HookResult {
+	input := envelope.toolInput()
+
+	matched, ok := matchPluginTool(plugin, "PreToolUse", &input)
+	if !ok {
+		return skipped()
+	}
+	input.Matched = matched
+
+	output, err := plugin.(PreToolUseHandler).PreToolUse(input)
+	return pluginResult(withDefault(output), err, fromPreToolUseOutput)
+}
+
+func handlePostToolUse(envelope *HookEnvelope, plugin IPlugin) *HookResult {
+	input := envelope.postToolUseInput()
+
+	matched, ok := matchPluginTool(plugin, "PostToolUse", &input.ToolInput)
+	if !ok {
+		return skipped()
+	}
+	input.Matched = matched
+
+	output, err := plugin.(PostToolUseHandler).PostToolUse(input)
+	return pluginResult(withDefault(output), err, fromPostToolUseOutput)
+}
+
+func handleNotification(envelope *HookEnvelope, plugin IPlugin) *HookResult {
+	input := NotificationInput{
+		BaseHookInput: envelope.base(),
+		Message:       envelope.Message,
+	}
+
+	output, err := plugin.(NotificationHandler).Notification(input)
+	return pluginResult(output, err, fromBaseOutput)
+}
+
+func handleStop(envelope *HookEnvelope, plugin IPlugin) *HookResult {
+	input := StopInput{
+		BaseHookInput:  envelope.base(),
+		StopHookActive: envelope.StopHookActive,
+	}
+
+	output, err := plugin.(StopHandler).Stop(input)
+	return pluginResult((*DecisionOutput)(withDefault(output)), err, fromDecisionOutput)
+}
+
+func handleSubagentStop(envelope *HookEnvelope, plugin IPlugin) *HookResult {
+	input := SubagentStopInput{
+		BaseHookInput:  envelope.base(),
+		StopHookActive: envelope.StopHookActive,
+	}
+
+	output, err := plugin.(SubagentStopHandler).SubagentStop(input)
+	return pluginResult(output, err, fromDecisionOutput)
+}
+
+func handlePreCompact(envelope *HookEnvelope, plugin IPlugin) *HookResult {
+	input := PreCompactInput{
+		BaseHookInput:      envelope.base(),
+		Trigger:            envelope.Trigger,
+		CustomInstructions: envelope.CustomInstructions,
+	}
+
+	matched, ok := matchPlugin(plugin, "PreCompact", input.Trigger)
+	if !ok {
+		return skipped()
+	}
+	input.Matched = matched
+
+	output, err := plugin.(PreCompactHandler).PreCompact(input)
+	return pluginResult(output, err, fromBaseOutput)
+}
+
+func handleSessionStart(envelope *HookEnvelope, plugin IPlugin) *HookResult {
+	input := SessionStartInput{
+		BaseHookInput: envelope.base(),
+		Source:        envelope.Source,
+	}
+
+	matched, ok := matchPlugin(plugin, "SessionStart", input.Source)
+	if !ok {
+		return skipped()
+	}
+	input.Matched = matched
+
+	output, err := plugin.(SessionStartHandler).SessionStart(input)
+	return pluginResult(output, err, fromBaseOutput)
+}
+
+func withDefault[T any, U interface {
+	*T
+	Default()
+}](input U) U {
+	if input != nil {
+		return input
+	}
+	var result U = new(T)
+	result.Default()
+	return result
+}
+
+// Serve 将插件作为独立的hook命令运行：从stdin读取输入，执行后以对应的退出码退出
+// 用于进程外插件，在插件的main函数中调用；支持 --output-mode json|exitcode 参数
+// 插件的配置文件按可执行文件名查找，见ConfigPaths
+func Serve(plugin IPlugin) {
+	mode := OutputModeJSON
+	for i := 1; i < len(os.Args); i++ {
+		if os.Args[i] != "--output-mode" || i+1 >= len(os.Args) {
+			continue
+		}
+		parsed, err := ParseOutputMode(os.Args[i+1])
+		if err != nil {
+			fail(mode, err)
+		}
+		mode = parsed
+	}
+
+	// 配置文件以可执行文件名命名
+	if err := ConfigurePlugin(plugin, filepath.Base(os.Args[0])); err != nil {
+		fail(mode, fmt.Errorf("failed to configure plugin: %w", err))
+	}
+	if err := plugin.Initialize(); err != nil {
+		fail(mode, fmt.Errorf("failed to initialize plugin: %w", err))
+	}
+
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fail(mode, fmt.Errorf("failed to read stdin: %w", err))
+	}
+
+	result, err := RunHook([]IPlugin{plugin}, data)
+	if err != nil {
+		result = &HookResult{Err: err}
+	}
+	_ = plugin.Cleanup()
+	result.Exit(mode)
+}
+
+func fail(mode OutputMode, err error) {
+	(&HookResult{Err: err}).Exit(mode)
+}
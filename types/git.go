@@ -0,0 +1,120 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GitRepo 包含某个目录的git仓库，通过读取.git目录获得，不启动git进程
+type GitRepo struct {
+	// 工作树根目录
+	Root string
+	// git目录；工作树和子模块中是.git文件指向的目录
+	GitDir string
+}
+
+// FindGitRepo 从dir向上查找git仓库，不在仓库中时返回false
+func FindGitRepo(dir string) (*GitRepo, bool) {
+	for dir != "" {
+		gitPath := filepath.Join(dir, ".git")
+		if info, err := os.Stat(gitPath); err == nil {
+			if info.IsDir() {
+				return &GitRepo{Root: dir, GitDir: gitPath}, true
+			}
+			// 工作树和子模块的.git是指向实际目录的文件：gitdir: <path>
+			data, err := os.ReadFile(gitPath)
+			if err != nil {
+				return nil, false
+			}
+			gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+			if !ok {
+				return nil, false
+			}
+			if !filepath.IsAbs(gitDir) {
+				gitDir = filepath.Join(dir, gitDir)
+			}
+			return &GitRepo{Root: dir, GitDir: gitDir}, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return nil, false
+}
+
+// Branch 读取HEAD返回当前分支，HEAD分离时返回空字符串
+func (r *GitRepo) Branch() string {
+	head, err := os.ReadFile(filepath.Join(r.GitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	branch, ok := strings.CutPrefix(strings.TrimSpace(string(head)), "ref: refs/heads/")
+	if !ok {
+		return ""
+	}
+	return branch
+}
+
+// CurrentBranch 返回dir所在仓库的当前分支，HEAD分离或不在仓库中时返回空字符串
+func CurrentBranch(dir string) string {
+	repo, ok := FindGitRepo(dir)
+	if !ok {
+		return ""
+	}
+	return repo.Branch()
+}
+
+// GitCommand Bash命令中的一条git命令
+type GitCommand struct {
+	// -C指定的目录，为空表示命令的工作目录；多个-C按顺序拼接
+	Dir        string
+	Subcommand string
+	// 子命令的参数
+	Args []string
+}
+
+// gitValueOptions 需要参数值的git全局选项
+var gitValueOptions = map[string]bool{"-C": true, "-c": true, "--git-dir": true, "--work-tree": true, "--namespace": true, "--config-env": true}
+
+// ParseGitCommand 跳过git的全局选项，返回子命令及其参数；c应先Unwrap，不是git命令时返回false
+func ParseGitCommand(c ShellCommand) (GitCommand, bool) {
+	if c.Name() != "git" {
+		return GitCommand{}, false
+	}
+	var git GitCommand
+	args := c.Args[1:]
+	for len(args) > 0 {
+		arg := args[0]
+		if !strings.HasPrefix(arg, "-") {
+			git.Subcommand, git.Args = arg, args[1:]
+			return git, true
+		}
+		args = args[1:]
+		if gitValueOptions[arg] && len(args) > 0 {
+			if arg == "-C" {
+				if filepath.IsAbs(args[0]) {
+					git.Dir = args[0]
+				} else {
+					git.Dir = filepath.Join(git.Dir, args[0])
+				}
+			}
+			args = args[1:]
+		}
+	}
+	return GitCommand{}, false
+}
+
+// WorkDir 返回git命令实际的工作目录，cwd为命令的工作目录
+func (g GitCommand) WorkDir(cwd string) string {
+	if g.Dir == "" {
+		return cwd
+	}
+	if filepath.IsAbs(g.Dir) || cwd == "" {
+		return g.Dir
+	}
+	return filepath.Join(cwd, g.Dir)
+}
@@ -0,0 +1,132 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCurrentBranch(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("repo/.git/HEAD", "ref: refs/heads/main\n")
+	write("repo/src/main.go", "")
+	write("worktree/.git", "gitdir: ../repo/.git/worktrees/wt\n")
+	write("repo/.git/worktrees/wt/HEAD", "ref: refs/heads/feature/x\n")
+	write("detached/.git/HEAD", "0123456789abcdef0123456789abcdef01234567\n")
+
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{"repo", "main"},
+		{"repo/src", "main"},
+		{"worktree", "feature/x"},
+		{"detached", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := CurrentBranch(filepath.Join(dir, tt.dir)); got != tt.want {
+			t.Errorf("CurrentBranch(%s) = %q, want %q", tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestParseGitCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    GitCommand
+		ok      bool
+	}{
+		{"git status", GitCommand{Subcommand: "status", Args: []string{}}, true},
+		{"git -C sub -c core.pager=cat --no-pager commit -m msg", GitCommand{Dir: "sub", Subcommand: "commit", Args: []string{"-m", "msg"}}, true},
+		{"git -C a -C b log", GitCommand{Dir: "a/b", Subcommand: "log", Args: []string{}}, true},
+		{"git -C /repo push", GitCommand{Dir: "/repo", Subcommand: "push", Args: []string{}}, true},
+		{"git --version", GitCommand{}, false},
+		{"gitk --all", GitCommand{}, false},
+	}
+	for _, tt := range tests {
+		commands, err := ParseShell(tt.command)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := ParseGitCommand(commands[0])
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseGitCommand(%q) = %#v, %v; want %#v, %v", tt.command, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFindGitRepo(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"repo/.git/HEAD":          "ref: refs/heads/main\n",
+		"worktree/.git":           "gitdir: ../repo/.git/worktrees/wt\n",
+		"submodule/.git":          "gitdir: /modules/sub\n",
+		"broken/.git":             "not a gitdir file\n",
+		"repo/nested/.git/HEAD":   "ref: refs/heads/nested\n",
+		"repo/nested/src/main.go": "",
+	} {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		dir    string
+		root   string
+		gitDir string
+		ok     bool
+	}{
+		{"repo", "repo", filepath.Join(dir, "repo/.git"), true},
+		{"repo/missing/dir", "repo", filepath.Join(dir, "repo/.git"), true},
+		{"repo/nested/src", "repo/nested", filepath.Join(dir, "repo/nested/.git"), true},
+		{"worktree", "worktree", filepath.Join(dir, "repo/.git/worktrees/wt"), true},
+		{"submodule", "submodule", "/modules/sub", true},
+		{"broken", "", "", false},
+	}
+	for _, tt := range tests {
+		repo, ok := FindGitRepo(filepath.Join(dir, tt.dir))
+		if ok != tt.ok {
+			t.Errorf("FindGitRepo(%s) ok = %v, want %v", tt.dir, ok, tt.ok)
+			continue
+		}
+		if ok && (repo.Root != filepath.Join(dir, tt.root) || repo.GitDir != tt.gitDir) {
+			t.Errorf("FindGitRepo(%s) = %+v, want root %s, git dir %s", tt.dir, *repo, tt.root, tt.gitDir)
+		}
+	}
+}
+
+func TestGitCommandWorkDir(t *testing.T) {
+	tests := []struct {
+		dir  string
+		cwd  string
+		want string
+	}{
+		{"", "/project", "/project"},
+		{"sub", "/project", "/project/sub"},
+		{"../other", "/project", "/other"},
+		{"/repo", "/project", "/repo"},
+		{"sub", "", "sub"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		if got := (GitCommand{Dir: tt.dir}).WorkDir(tt.cwd); got != tt.want {
+			t.Errorf("WorkDir(%q) with -C %q = %q, want %q", tt.cwd, tt.dir, got, tt.want)
+		}
+	}
+}
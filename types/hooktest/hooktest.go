@@ -0,0 +1,154 @@
+// Package hooktest 提供基于golden文件的插件回归测试工具
+//
+// 夹具目录中的每个 <case>.json 文件是一次hook事件的输入，
+// 对应的 <case>.golden 文件记录期望的退出码与输出。
+// 使用 go test -update 重新生成golden文件。
+package hooktest
+
+import (
+	"bytes"
+	"claude-hooks/types"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// Factory 插件构造函数，与插件导出的New函数签名一致
+type Factory func() types.IPlugin
+
+// Output 一次hook执行的结果，即golden文件的内容
+type Output struct {
+	Code  int             `json:"code"`
+	Data  json.RawMessage `json:"data,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
+
+// Execute 使用给定插件执行一次hook输入
+func Execute(plugins []types.IPlugin, input []byte) Output {
+	result, err := types.ExecuteHook(plugins, input)
+	if err != nil {
+		result = types.NewError(err.Error())
+	}
+
+	out := Output{Code: result.Code, Error: result.Error}
+	if result.Data != "" {
+		if json.Valid([]byte(result.Data)) {
+			out.Data = json.RawMessage(result.Data)
+		} else {
+			out.Data, _ = json.Marshal(result.Data)
+		}
+	}
+	return out
+}
+
+// Run 通过工厂函数创建插件，并对dir中的所有夹具进行golden比较
+func Run(t *testing.T, factory Factory, dir string) {
+	t.Helper()
+	RunConfig(t, factory, dir, nil)
+}
+
+// RunConfig 与Run相同，但在初始化前用config配置插件，插件必须实现types.Configurable
+// config为nil时不配置插件
+func RunConfig(t *testing.T, factory Factory, dir string, config json.RawMessage) {
+	t.Helper()
+
+	plugin := factory()
+	if plugin == nil {
+		t.Fatal("factory returned nil plugin")
+	}
+	if config != nil {
+		configurable, ok := plugin.(types.Configurable)
+		if !ok {
+			t.Fatal("plugin does not implement types.Configurable")
+		}
+		if err := configurable.Configure(config); err != nil {
+			t.Fatalf("failed to configure plugin: %v", err)
+		}
+	}
+	if err := plugin.Initialize(); err != nil {
+		t.Fatalf("failed to initialize plugin: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := plugin.Cleanup(); err != nil {
+			t.Errorf("failed to cleanup plugin: %v", err)
+		}
+	})
+
+	RunPlugins(t, []types.IPlugin{plugin}, dir)
+}
+
+// RunSO 从.so文件加载插件，并对dir中的所有夹具进行golden比较
+func RunSO(t *testing.T, path string, dir string) {
+	t.Helper()
+
+	pm := types.NewPluginManager("")
+	if err := pm.LoadPlugin(path); err != nil {
+		t.Fatalf("failed to load plugin: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := pm.Shutdown(); err != nil {
+			t.Errorf("failed to shutdown plugins: %v", err)
+		}
+	})
+
+	RunPlugins(t, pm.Plugins(), dir)
+}
+
+// RunPlugins 使用已初始化的插件对dir中的所有夹具进行golden比较
+func RunPlugins(t *testing.T, plugins []types.IPlugin, dir string) {
+	t.Helper()
+
+	fixtures, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatalf("failed to scan fixtures: %v", err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatalf("no fixtures found in %s", dir)
+	}
+	sort.Strings(fixtures)
+
+	for _, fixture := range fixtures {
+		name := strings.TrimSuffix(filepath.Base(fixture), ".json")
+		t.Run(name, func(t *testing.T) {
+			input, err := os.ReadFile(fixture)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+
+			got, err := marshalOutput(Execute(plugins, input))
+			if err != nil {
+				t.Fatalf("failed to marshal output: %v", err)
+			}
+
+			goldenPath := strings.TrimSuffix(fixture, ".json") + ".golden"
+			if *update {
+				if err := os.WriteFile(goldenPath, got, 0644); err != nil {
+					t.Fatalf("failed to write golden file: %v", err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("failed to read golden file (run with -update to create it): %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("output mismatch for %s\n--- got\n%s\n--- want\n%s", fixture, got, want)
+			}
+		})
+	}
+}
+
+func marshalOutput(out Output) ([]byte, error) {
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,196 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// HookEnvelope 解码后的hook输入，包含所有事件的字段
+// 输入只解码一次，再按事件构造传给插件的输入
+type HookEnvelope struct {
+	BaseHookInput
+
+	// PreToolUse/PostToolUse
+	ToolName     string
+	ToolInput    map[string]any
+	ToolResponse map[string]any
+	// Notification
+	Message string
+	// Stop/SubagentStop
+	StopHookActive bool
+	// PreCompact
+	Trigger            string
+	CustomInstructions string
+	// SessionStart
+	Source string
+}
+
+// DecodeEnvelope 解码hook输入，未知字段保存在Extra中
+// 字段类型错误时返回带字段名的错误，JSON语法错误时返回出错位置
+func DecodeEnvelope(data []byte) (*HookEnvelope, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, errors.New("invalid hook input: empty input")
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, decodeError("", data, err)
+	}
+	if fields == nil {
+		return nil, errors.New("invalid hook input: expected a JSON object, got null")
+	}
+
+	e := &HookEnvelope{}
+	known := map[string]any{
+		"session_id":          &e.SessionID,
+		"transcript_path":     &e.TranscriptPath,
+		"hook_event_name":     &e.HookEventName,
+		"cwd":                 &e.Cwd,
+		"permission_mode":     &e.PermissionMode,
+		"tool_name":           &e.ToolName,
+		"tool_input":          &e.ToolInput,
+		"tool_response":       &e.ToolResponse,
+		"message":             &e.Message,
+		"stop_hook_active":    &e.StopHookActive,
+		"trigger":             &e.Trigger,
+		"custom_instructions": &e.CustomInstructions,
+		"source":              &e.Source,
+	}
+
+	for key, raw := range fields {
+		dst, ok := known[key]
+		if !ok {
+			if e.Extra == nil {
+				e.Extra = make(map[string]json.RawMessage)
+			}
+			e.Extra[key] = raw
+			continue
+		}
+		if err := json.Unmarshal(raw, dst); err != nil {
+			return nil, decodeError(key, raw, err)
+		}
+	}
+	e.Raw = json.RawMessage(data)
+
+	if e.HookEventName == "" {
+		return nil, errors.New("invalid hook input: missing hook_event_name")
+	}
+	if (e.HookEventName == "PreToolUse" || e.HookEventName == "PostToolUse") && e.ToolName == "" {
+		return nil, fmt.Errorf("invalid hook input: %s requires tool_name", e.HookEventName)
+	}
+	return e, nil
+}
+
+// decodeError 将JSON解码错误转换为指出字段或位置的错误
+func decodeError(field string, data []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		// Offset指向出错字符之后
+		line, col := position(data, syntaxErr.Offset-1)
+		return fmt.Errorf("invalid hook input: malformed JSON at line %d, column %d: %v", line, col, syntaxErr)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		if field == "" {
+			return fmt.Errorf("invalid hook input: expected a JSON object, got %s", typeErr.Value)
+		}
+		if typeErr.Field != "" {
+			field += "." + typeErr.Field
+		}
+		return fmt.Errorf("invalid hook input: field %q must be %s, got %s", field, jsonTypeName(typeErr.Type), typeErr.Value)
+	}
+
+	if field != "" {
+		return fmt.Errorf("invalid hook input: field %q: %w", field, err)
+	}
+	return fmt.Errorf("invalid hook input: %w", err)
+}
+
+// position 将字节偏移转换为行列号
+func position(data []byte, offset int64) (line, col int) {
+	offset = max(0, min(offset, int64(len(data))))
+	line, col = 1, 1
+	for _, c := range data[:offset] {
+		if c == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "a number"
+	}
+}
+
+// base 返回传给插件的公共字段，Extra为副本
+func (e *HookEnvelope) base() BaseHookInput {
+	base := e.BaseHookInput
+	if e.Extra != nil {
+		base.Extra = make(map[string]json.RawMessage, len(e.Extra))
+		for k, v := range e.Extra {
+			base.Extra[k] = v
+		}
+	}
+	return base
+}
+
+// toolInput 返回传给插件的工具输入，map为深拷贝，插件的修改不会影响其他插件
+func (e *HookEnvelope) toolInput() ToolInput {
+	return ToolInput{
+		BaseHookInput: e.base(),
+		ToolName:      e.ToolName,
+		ToolInput:     cloneMap(e.ToolInput),
+	}
+}
+
+func (e *HookEnvelope) postToolUseInput() PostToolUseInput {
+	return PostToolUseInput{
+		ToolInput:    e.toolInput(),
+		ToolResponse: cloneMap(e.ToolResponse),
+	}
+}
+
+func cloneMap(m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+	clone := make(map[string]any, len(m))
+	for k, v := range m {
+		clone[k] = cloneValue(v)
+	}
+	return clone
+}
+
+func cloneValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		return cloneMap(v)
+	case []any:
+		clone := make([]any, len(v))
+		for i, item := range v {
+			clone[i] = cloneValue(item)
+		}
+		return clone
+	default:
+		return v
+	}
+}
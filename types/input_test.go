@@ -0,0 +1,107 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeEnvelope(t *testing.T) {
+	data := `{
+		"session_id": "s1",
+		"hook_event_name": "PreToolUse",
+		"cwd": "/project",
+		"permission_mode": "plan",
+		"tool_name": "Bash",
+		"tool_input": {"command": "ls"},
+		"future_field": {"nested": true}
+	}`
+
+	e, err := DecodeEnvelope([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Cwd != "/project" || e.PermissionMode != "plan" || e.ToolName != "Bash" {
+		t.Errorf("envelope = %+v", e)
+	}
+	if e.ToolInput["command"] != "ls" {
+		t.Errorf("tool_input = %v", e.ToolInput)
+	}
+	if len(e.Raw) == 0 {
+		t.Error("Raw is empty")
+	}
+
+	var future struct {
+		Nested bool `json:"nested"`
+	}
+	ok, err := e.ExtraField("future_field", &future)
+	if !ok || err != nil || !future.Nested {
+		t.Errorf("ExtraField = %v, %v, %+v", ok, err, future)
+	}
+	if ok, _ := e.ExtraField("missing", &future); ok {
+		t.Error("ExtraField reported a missing field")
+	}
+	if _, known := e.Extra["cwd"]; known {
+		t.Error("known field cwd stored in Extra")
+	}
+}
+
+func TestDecodeEnvelopeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"empty", "  ", "empty input"},
+		{"syntax", "{\n  \"hook_event_name\": \"Stop\",\n  oops\n}", "malformed JSON at line 3, column 3"},
+		{"not an object", `["Stop"]`, "expected a JSON object, got array"},
+		{"null", `null`, "expected a JSON object, got null"},
+		{"wrong field type", `{"hook_event_name":"PreToolUse","tool_name":"Bash","tool_input":"ls"}`, `field "tool_input" must be an object, got string`},
+		{"wrong bool type", `{"hook_event_name":"Stop","stop_hook_active":"yes"}`, `field "stop_hook_active" must be a boolean, got string`},
+		{"missing event", `{"session_id":"s1"}`, "missing hook_event_name"},
+		{"missing tool name", `{"hook_event_name":"PostToolUse","tool_input":{}}`, "PostToolUse requires tool_name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeEnvelope([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// mutatingPlugin 修改收到的工具输入，并记录收到的输入
+type mutatingPlugin struct {
+	UnimplementedPlugin
+	seen     []ToolInput
+	commands []any
+}
+
+func (p *mutatingPlugin) GetMetadata() PluginMetadata {
+	return PluginMetadata{}
+}
+
+func (p *mutatingPlugin) PreToolUse(arg ToolInput) (*PreToolUseOutput, error) {
+	p.seen = append(p.seen, arg)
+	p.commands = append(p.commands, arg.ToolInput["command"])
+	arg.ToolInput["command"] = "rm -rf /"
+	return nil, nil
+}
+
+func TestRunHookIsolatesPluginInput(t *testing.T) {
+	first, second := &mutatingPlugin{}, &mutatingPlugin{}
+	data := `{"hook_event_name":"PreToolUse","permission_mode":"acceptEdits","tool_name":"Bash","tool_input":{"command":"ls"},"new_field":1}`
+
+	if _, err := RunHook([]IPlugin{first, second}, []byte(data)); err != nil {
+		t.Fatal(err)
+	}
+
+	if second.commands[0] != "ls" {
+		t.Errorf("second plugin saw command %v, want ls", second.commands[0])
+	}
+	got := second.seen[0]
+	if got.PermissionMode != "acceptEdits" || string(got.Extra["new_field"]) != "1" {
+		t.Errorf("PermissionMode = %q, Extra = %v", got.PermissionMode, got.Extra)
+	}
+}
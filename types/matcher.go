@@ -0,0 +1,281 @@
+package types
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Matcher 声明插件在某个hook事件上的匹配器
+// 同一事件可以声明多个匹配器，插件通过MatchedBy区分本次调用由哪个匹配器触发
+type Matcher struct {
+	// hook事件名，如PreToolUse
+	Event string
+	// 匹配的正则表达式，""或"*"匹配全部
+	//  - PreToolUse/PostToolUse: 工具名
+	//  - PreCompact: trigger (manual/auto)
+	//  - SessionStart: source (startup/resume/clear/compact)
+	Pattern string
+	// 匹配器标识，为空时使用Pattern
+	ID string
+
+	// 以下条件只对PreToolUse/PostToolUse生效，每类条件满足其中任意一项即可，所有设置了的条件都满足时才匹配
+	// 文件glob，相对项目根目录匹配file_path/notebook_path/path，支持**；不含/的模式只匹配文件名
+	FileGlobs []string
+	// 相对项目根目录的路径前缀
+	PathPrefixes []string
+	// Bash命令的正则，在命令中搜索
+	CommandPatterns []string
+	// MCP服务器名，匹配 mcp__<server>__<tool> 形式的工具
+	MCPServers []string
+}
+
+// OnPreToolUse 创建PreToolUse匹配器
+func OnPreToolUse(pattern string) Matcher {
+	return Matcher{Event: "PreToolUse", Pattern: pattern}
+}
+
+// OnPostToolUse 创建PostToolUse匹配器
+func OnPostToolUse(pattern string) Matcher {
+	return Matcher{Event: "PostToolUse", Pattern: pattern}
+}
+
+// OnPreCompact 创建PreCompact匹配器，trigger为manual或auto
+func OnPreCompact(trigger string) Matcher {
+	return Matcher{Event: "PreCompact", Pattern: trigger}
+}
+
+// OnSessionStart 创建SessionStart匹配器，source为startup、resume、clear或compact
+func OnSessionStart(source string) Matcher {
+	return Matcher{Event: "SessionStart", Pattern: source}
+}
+
+// OnEvent 创建任意事件的匹配器
+func OnEvent(event string, pattern string) Matcher {
+	return Matcher{Event: event, Pattern: pattern}
+}
+
+// WithID 为匹配器设置标识，用于在同一事件的多个匹配器间区分行为
+func (m Matcher) WithID(id string) Matcher {
+	m.ID = id
+	return m
+}
+
+// WithFiles 限制只匹配路径符合glob的文件，如 "**/*.go"
+func (m Matcher) WithFiles(globs ...string) Matcher {
+	m.FileGlobs = append(append([]string{}, m.FileGlobs...), globs...)
+	return m
+}
+
+// WithPathPrefix 限制只匹配项目根目录下指定前缀的路径，如 "internal/"
+func (m Matcher) WithPathPrefix(prefixes ...string) Matcher {
+	m.PathPrefixes = append(append([]string{}, m.PathPrefixes...), prefixes...)
+	return m
+}
+
+// WithCommand 限制只匹配命令符合正则的Bash调用
+func (m Matcher) WithCommand(patterns ...string) Matcher {
+	m.CommandPatterns = append(append([]string{}, m.CommandPatterns...), patterns...)
+	return m
+}
+
+// WithMCPServer 限制只匹配指定MCP服务器的工具
+func (m Matcher) WithMCPServer(servers ...string) Matcher {
+	m.MCPServers = append(append([]string{}, m.MCPServers...), servers...)
+	return m
+}
+
+// Key 返回匹配器标识，未设置ID时为Pattern
+func (m Matcher) Key() string {
+	if m.ID != "" {
+		return m.ID
+	}
+	return m.Pattern
+}
+
+// Match 判断subject是否匹配，与Claude Code一致，正则需要匹配完整的subject
+func (m Matcher) Match(subject string) bool {
+	if m.Pattern == "" || m.Pattern == "*" {
+		return true
+	}
+	re, err := regexp.Compile("^(?:" + m.Pattern + ")$")
+	if err != nil {
+		return false
+	}
+	return re.MatchString(subject)
+}
+
+// MatchTool 判断工具调用是否匹配，除工具名外还会检查文件、路径、命令和MCP服务器条件
+func (m Matcher) MatchTool(input *ToolInput) bool {
+	if !m.Match(input.ToolName) {
+		return false
+	}
+
+	if len(m.FileGlobs) > 0 || len(m.PathPrefixes) > 0 {
+		path := input.GetTargetPath()
+		if path == "" {
+			return false
+		}
+		rel := RelativeToProject(path, input.ProjectDir())
+
+		if len(m.FileGlobs) > 0 && !matchAny(m.FileGlobs, func(glob string) bool { return MatchGlob(glob, rel) }) {
+			return false
+		}
+		if len(m.PathPrefixes) > 0 && !matchAny(m.PathPrefixes, func(prefix string) bool { return hasPathPrefix(rel, prefix) }) {
+			return false
+		}
+	}
+
+	if len(m.CommandPatterns) > 0 {
+		command, _ := input.ToolInput["command"].(string)
+		if input.ToolName != "Bash" || !matchAny(m.CommandPatterns, func(pattern string) bool {
+			re, err := regexp.Compile(pattern)
+			return err == nil && re.MatchString(command)
+		}) {
+			return false
+		}
+	}
+
+	if len(m.MCPServers) > 0 {
+		server := MCPServerName(input.ToolName)
+		if server == "" || !matchAny(m.MCPServers, func(s string) bool { return s == server }) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchAny(values []string, match func(string) bool) bool {
+	for _, v := range values {
+		if match(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// MCPServerName 返回MCP工具所属的服务器名，非MCP工具返回空字符串
+func MCPServerName(toolName string) string {
+	rest, ok := strings.CutPrefix(toolName, "mcp__")
+	if !ok {
+		return ""
+	}
+	server, _, ok := strings.Cut(rest, "__")
+	if !ok {
+		return ""
+	}
+	return server
+}
+
+// MatchGlob 判断路径是否匹配glob，*和?不跨越目录，**匹配任意层目录
+// 不含/的模式只匹配文件名
+func MatchGlob(glob string, path string) bool {
+	path = filepath.ToSlash(path)
+	if !strings.Contains(glob, "/") {
+		path = filepath.Base(path)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+
+	re, err := regexp.Compile(sb.String())
+	return err == nil && re.MatchString(path)
+}
+
+// RelativeToProject 返回相对项目根目录、以/分隔的路径，用于匹配glob；不在项目内时返回清理后的绝对路径
+func RelativeToProject(path string, projectDir string) string {
+	if projectDir == "" {
+		return filepath.ToSlash(filepath.Clean(path))
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(projectDir, path)
+	}
+	rel, err := filepath.Rel(projectDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(filepath.Clean(path))
+	}
+	return filepath.ToSlash(rel)
+}
+
+func hasPathPrefix(rel string, prefix string) bool {
+	prefix = strings.TrimSuffix(filepath.ToSlash(filepath.Clean(prefix)), "/")
+	if prefix == "." {
+		return !filepath.IsAbs(rel)
+	}
+	return rel == prefix || strings.HasPrefix(rel, prefix+"/")
+}
+
+// Tools 将多个工具名组合为匹配器正则，如 Tools("Write", "Edit") 返回 "Write|Edit"
+func Tools(names ...string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, regexp.QuoteMeta(name))
+	}
+	return strings.Join(quoted, "|")
+}
+
+// AllMatchers 返回插件声明的所有匹配器，包括旧的Matcher字段
+func (m PluginMetadata) AllMatchers() []Matcher {
+	matchers := make([]Matcher, 0, len(m.Matchers)+2)
+	if m.Matcher.PreToolUse != "" {
+		matchers = append(matchers, OnPreToolUse(m.Matcher.PreToolUse))
+	}
+	if m.Matcher.PostToolUse != "" {
+		matchers = append(matchers, OnPostToolUse(m.Matcher.PostToolUse))
+	}
+	return append(matchers, m.Matchers...)
+}
+
+// MatchersFor 返回插件在指定事件上声明的匹配器
+func (m PluginMetadata) MatchersFor(event string) []Matcher {
+	var matchers []Matcher
+	for _, matcher := range m.AllMatchers() {
+		if matcher.Event == event {
+			matchers = append(matchers, matcher)
+		}
+	}
+	return matchers
+}
+
+// matchPlugin 返回插件在该事件上与subject匹配的匹配器标识
+// 插件在该事件上声明了匹配器但都不匹配时，ok为false，表示不应调用插件
+func matchPlugin(plugin IPlugin, event string, subject string) (matched []string, ok bool) {
+	return matchPluginWith(plugin, event, func(m Matcher) bool { return m.Match(subject) })
+}
+
+// matchPluginTool 与matchPlugin相同，但同时检查工具调用的条件
+func matchPluginTool(plugin IPlugin, event string, input *ToolInput) (matched []string, ok bool) {
+	return matchPluginWith(plugin, event, func(m Matcher) bool { return m.MatchTool(input) })
+}
+
+func matchPluginWith(plugin IPlugin, event string, match func(Matcher) bool) (matched []string, ok bool) {
+	matchers := plugin.GetMetadata().MatchersFor(event)
+	if len(matchers) == 0 {
+		return nil, true
+	}
+	for _, matcher := range matchers {
+		if match(matcher) {
+			matched = append(matched, matcher.Key())
+		}
+	}
+	return matched, len(matched) > 0
+}
@@ -0,0 +1,176 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatcherMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		subject string
+		want    bool
+	}{
+		{"", "Bash", true},
+		{"*", "Bash", true},
+		{"Write", "Write", true},
+		{"Write", "NotebookWrite", false},
+		{"Edit|Write", "Edit", true},
+		{"Notebook.*", "NotebookEdit", true},
+		{"mcp__github__.*", "mcp__github__create_issue", true},
+		{"Bash(", "Bash", false},
+	}
+
+	for _, tt := range tests {
+		if got := OnPreToolUse(tt.pattern).Match(tt.subject); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.subject, got, tt.want)
+		}
+	}
+}
+
+func TestTools(t *testing.T) {
+	if got, want := Tools("Write", "Edit", "mcp__a.b"), `Write|Edit|mcp__a\.b`; got != want {
+		t.Errorf("Tools = %q, want %q", got, want)
+	}
+}
+
+func TestAllMatchersIncludesLegacyMatcher(t *testing.T) {
+	var metadata PluginMetadata
+	metadata.Matcher.PreToolUse = "Read"
+	metadata.Matchers = []Matcher{OnPreCompact("manual").WithID("compact")}
+
+	want := []Matcher{
+		{Event: "PreToolUse", Pattern: "Read"},
+		{Event: "PreCompact", Pattern: "manual", ID: "compact"},
+	}
+	if got := metadata.AllMatchers(); !reflect.DeepEqual(got, want) {
+		t.Errorf("AllMatchers = %+v, want %+v", got, want)
+	}
+}
+
+type multiMatcherPlugin struct {
+	UnimplementedPlugin
+	matched [][]string
+}
+
+func (p *multiMatcherPlugin) GetMetadata() PluginMetadata {
+	return PluginMetadata{
+		Matchers: []Matcher{
+			OnPreToolUse("Bash").WithID("shell"),
+			OnPreToolUse(Tools("Write", "Edit")).WithID("files"),
+			OnPreToolUse("Edit"),
+		},
+	}
+}
+
+func (p *multiMatcherPlugin) PreToolUse(arg ToolInput) (*PreToolUseOutput, error) {
+	p.matched = append(p.matched, arg.Matched)
+	if arg.MatchedBy("shell") {
+		var ret PreToolUseOutput
+		return ret.Approve(false, "no shell"), nil
+	}
+	return nil, nil
+}
+
+func TestExecuteHookMatchers(t *testing.T) {
+	plugin := &multiMatcherPlugin{}
+	plugins := []IPlugin{plugin}
+
+	result, err := ExecuteHook(plugins, []byte(`{"hook_event_name":"PreToolUse","tool_name":"Bash"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != ExitCodeBlockingError {
+		t.Errorf("Bash result code = %d, want %d", result.Code, ExitCodeBlockingError)
+	}
+
+	if _, err := ExecuteHook(plugins, []byte(`{"hook_event_name":"PreToolUse","tool_name":"Edit"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Read没有匹配任何匹配器，不应调用插件
+	if _, err := ExecuteHook(plugins, []byte(`{"hook_event_name":"PreToolUse","tool_name":"Read"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{{"shell"}, {"files", "Edit"}}
+	if !reflect.DeepEqual(plugin.matched, want) {
+		t.Errorf("matched = %v, want %v", plugin.matched, want)
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		glob string
+		path string
+		want bool
+	}{
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "types/plugin.go", true},
+		{"**/*.go", "README.md", false},
+		{"*.go", "types/plugin.go", true},
+		{"types/*.go", "types/plugin.go", true},
+		{"types/*.go", "types/hooktest/hooktest.go", false},
+		{"types/**", "types/hooktest/hooktest.go", true},
+		{"**/testdata/*.json", "plugins/env/testdata/read_env.json", true},
+		{"?.go", "ab.go", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchGlob(tt.glob, tt.path); got != tt.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.glob, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestMatcherMatchTool(t *testing.T) {
+	t.Setenv("CLAUDE_PROJECT_DIR", "")
+
+	toolInput := func(tool string, input map[string]any) *ToolInput {
+		return &ToolInput{
+			BaseHookInput: BaseHookInput{Cwd: "/project"},
+			ToolName:      tool,
+			ToolInput:     input,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		matcher Matcher
+		input   *ToolInput
+		want    bool
+	}{
+		{"go file", OnPostToolUse("Write|Edit").WithFiles("**/*.go"),
+			toolInput("Edit", map[string]any{"file_path": "/project/cmd/main.go"}), true},
+		{"markdown file", OnPostToolUse("Write|Edit").WithFiles("**/*.go"),
+			toolInput("Edit", map[string]any{"file_path": "/project/README.md"}), false},
+		{"no file path", OnPostToolUse("*").WithFiles("**/*.go"),
+			toolInput("Bash", map[string]any{"command": "ls"}), false},
+		{"relative path", OnPreToolUse("Write").WithFiles("**/*.go"),
+			toolInput("Write", map[string]any{"file_path": "main.go"}), true},
+		{"path prefix", OnPreToolUse("Edit").WithPathPrefix("internal/"),
+			toolInput("Edit", map[string]any{"file_path": "/project/internal/db/db.go"}), true},
+		{"path prefix segment", OnPreToolUse("Edit").WithPathPrefix("internal"),
+			toolInput("Edit", map[string]any{"file_path": "/project/internalx/db.go"}), false},
+		{"outside project", OnPreToolUse("Edit").WithPathPrefix("."),
+			toolInput("Edit", map[string]any{"file_path": "/etc/hosts"}), false},
+		{"grep path", OnPreToolUse("Grep").WithFiles(".env*"),
+			toolInput("Grep", map[string]any{"pattern": "SECRET", "path": "/project/.env.local"}), true},
+		{"bash command", OnPreToolUse("Bash").WithCommand(`\bgit\s+push\b`),
+			toolInput("Bash", map[string]any{"command": "git push --force"}), true},
+		{"other bash command", OnPreToolUse("Bash").WithCommand(`\bgit\s+push\b`),
+			toolInput("Bash", map[string]any{"command": "git status"}), false},
+		{"mcp server", OnPreToolUse("mcp__.*").WithMCPServer("github"),
+			toolInput("mcp__github__create_issue", map[string]any{}), true},
+		{"other mcp server", OnPreToolUse("mcp__.*").WithMCPServer("github"),
+			toolInput("mcp__slack__post", map[string]any{}), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.matcher.MatchTool(tt.input); got != tt.want {
+				t.Errorf("MatchTool = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package types
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// OutputMode hook结果返回给Claude Code的方式
+type OutputMode string
+
+const (
+	// OutputModeJSON 决策以JSON写到stdout并以0退出，由Claude Code解析
+	// 插件出错时错误写到stderr并以1退出
+	OutputModeJSON OutputMode = "json"
+	// OutputModeExitCode 只使用退出码：阻塞时原因写到stderr并以2退出
+	// continue、stopReason、suppressOutput、updatedInput等只能通过JSON表达的字段会被忽略，
+	// ask无法通过退出码表达，按阻塞处理
+	OutputModeExitCode OutputMode = "exitcode"
+)
+
+// ParseOutputMode 解析--output-mode参数
+func ParseOutputMode(s string) (OutputMode, error) {
+	switch mode := OutputMode(s); mode {
+	case OutputModeJSON, OutputModeExitCode:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid output mode: %q (expected json or exitcode)", s)
+	}
+}
+
+// Write 按输出模式将结果写到stdout/stderr，返回进程应使用的退出码
+func (r *HookResult) Write(mode OutputMode, stdout, stderr io.Writer) int {
+	if r.Err != nil {
+		writeMessage(stderr, r.Err.Error())
+		return ExitCodeError
+	}
+
+	if mode != OutputModeExitCode {
+		// 包括阻塞决策，Claude Code只在退出码为0时解析stdout中的JSON
+		data, err := r.JSON()
+		if err != nil {
+			writeMessage(stderr, fmt.Sprintf("failed to marshal result: %v", err))
+			return ExitCodeError
+		}
+		writeMessage(stdout, string(data))
+		return ExitCodeSuccess
+	}
+
+	switch r.Decision {
+	case DecisionBlock, DecisionAsk:
+		writeMessage(stderr, r.Reason)
+		return ExitCodeBlockingError
+	case DecisionApprove:
+		// 退出码为0时stdout显示给用户，只有approve的reason是给用户看的
+		writeMessage(stdout, r.Reason)
+	}
+	return ExitCodeSuccess
+}
+
+// Exit 按输出模式写出结果并退出进程
+func (r *HookResult) Exit(mode OutputMode) {
+	os.Exit(r.Write(mode, os.Stdout, os.Stderr))
+}
+
+func writeMessage(w io.Writer, msg string) {
+	msg = strings.TrimRight(msg, "\n")
+	if msg == "" {
+		return
+	}
+	_, _ = fmt.Fprintln(w, msg)
+}
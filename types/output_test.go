@@ -0,0 +1,162 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// allEventsPlugin 处理所有事件，用于检查各事件的输出
+type allEventsPlugin struct {
+	UnimplementedPlugin
+}
+
+func (p *allEventsPlugin) GetMetadata() PluginMetadata {
+	return PluginMetadata{}
+}
+
+func (p *allEventsPlugin) PreToolUse(arg ToolInput) (*PreToolUseOutput, error) {
+	var ret PreToolUseOutput
+	switch arg.ToolName {
+	case "Bash":
+		return ret.Approve(false, "no shell"), nil
+	case "Read":
+		return ret.Approve(true, "read ok"), nil
+	}
+	return nil, nil
+}
+
+func (p *allEventsPlugin) PostToolUse(arg PostToolUseInput) (*PostToolUseOutput, error) {
+	if arg.ToolName == "Fail" {
+		return nil, errors.New("boom")
+	}
+	var ret PostToolUseOutput
+	return ret.Block("lint failed"), nil
+}
+
+func (p *allEventsPlugin) Notification(arg NotificationInput) (*BaseHookOutput, error) {
+	return nil, nil
+}
+
+func (p *allEventsPlugin) Stop(arg StopInput) (*StopOutput, error) {
+	var ret StopOutput
+	ret.NotAllowed("keep going")
+	return &ret, nil
+}
+
+func (p *allEventsPlugin) SubagentStop(arg SubagentStopInput) (*DecisionOutput, error) {
+	return &DecisionOutput{Decision: ptr("block"), Reason: ptr("subagent unfinished")}, nil
+}
+
+func (p *allEventsPlugin) PreCompact(arg PreCompactInput) (*BaseHookOutput, error) {
+	return &BaseHookOutput{Continue: ptr(false), StopReason: "no compaction"}, nil
+}
+
+func (p *allEventsPlugin) SessionStart(arg SessionStartInput) (*BaseHookOutput, error) {
+	return nil, nil
+}
+
+func TestResultWrite(t *testing.T) {
+	type output struct {
+		stdout, stderr string
+		code           int
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		json     output
+		exitcode output
+	}{
+		{
+			name:     "PreToolUse block",
+			input:    `{"hook_event_name":"PreToolUse","tool_name":"Bash"}`,
+			json:     output{stdout: `{"decision":"block","reason":"no shell"}` + "\n"},
+			exitcode: output{stderr: "no shell\n", code: ExitCodeBlockingError},
+		},
+		{
+			name:     "PreToolUse approve",
+			input:    `{"hook_event_name":"PreToolUse","tool_name":"Read"}`,
+			json:     output{stdout: `{"decision":"approve","reason":"read ok"}` + "\n"},
+			exitcode: output{stdout: "read ok\n"},
+		},
+		{
+			// 没有决策时不输出任何内容，由Claude Code按权限设置确认
+			name:  "PreToolUse no decision",
+			input: `{"hook_event_name":"PreToolUse","tool_name":"Write"}`,
+		},
+		{
+			name:     "PostToolUse block",
+			input:    `{"hook_event_name":"PostToolUse","tool_name":"Write"}`,
+			json:     output{stdout: `{"decision":"block","reason":"lint failed"}` + "\n"},
+			exitcode: output{stderr: "lint failed\n", code: ExitCodeBlockingError},
+		},
+		{
+			name:     "PostToolUse plugin error",
+			input:    `{"hook_event_name":"PostToolUse","tool_name":"Fail"}`,
+			json:     output{stderr: "boom\n", code: ExitCodeError},
+			exitcode: output{stderr: "boom\n", code: ExitCodeError},
+		},
+		{
+			name:  "Notification",
+			input: `{"hook_event_name":"Notification","message":"hi"}`,
+		},
+		{
+			name:     "Stop",
+			input:    `{"hook_event_name":"Stop"}`,
+			json:     output{stdout: `{"decision":"block","reason":"keep going"}` + "\n"},
+			exitcode: output{stderr: "keep going\n", code: ExitCodeBlockingError},
+		},
+		{
+			name:     "SubagentStop",
+			input:    `{"hook_event_name":"SubagentStop"}`,
+			json:     output{stdout: `{"decision":"block","reason":"subagent unfinished"}` + "\n"},
+			exitcode: output{stderr: "subagent unfinished\n", code: ExitCodeBlockingError},
+		},
+		{
+			name:  "PreCompact continue false",
+			input: `{"hook_event_name":"PreCompact","trigger":"manual"}`,
+			json:  output{stdout: `{"continue":false,"stopReason":"no compaction"}` + "\n"},
+			// exitcode模式无法表达continue
+			exitcode: output{},
+		},
+		{
+			name:  "SessionStart",
+			input: `{"hook_event_name":"SessionStart","source":"startup"}`,
+		},
+		{
+			name:     "unknown event",
+			input:    `{"hook_event_name":"Unknown"}`,
+			json:     output{stderr: "unknown hook type: Unknown\n", code: ExitCodeError},
+			exitcode: output{stderr: "unknown hook type: Unknown\n", code: ExitCodeError},
+		},
+	}
+
+	plugins := []IPlugin{&allEventsPlugin{}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := RunHook(plugins, []byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for mode, want := range map[OutputMode]output{OutputModeJSON: tt.json, OutputModeExitCode: tt.exitcode} {
+				var stdout, stderr bytes.Buffer
+				code := result.Write(mode, &stdout, &stderr)
+				got := output{stdout: stdout.String(), stderr: stderr.String(), code: code}
+				if got != want {
+					t.Errorf("%s mode: got %+v, want %+v", mode, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseOutputMode(t *testing.T) {
+	if mode, err := ParseOutputMode("exitcode"); err != nil || mode != OutputModeExitCode {
+		t.Errorf("ParseOutputMode(exitcode) = %q, %v", mode, err)
+	}
+	if _, err := ParseOutputMode("text"); err == nil {
+		t.Error("ParseOutputMode(text) succeeded, want error")
+	}
+}
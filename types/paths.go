@@ -0,0 +1,52 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TempDirs 返回临时目录：/tmp，以及TMPDIR指向的不同目录
+func TempDirs() []string {
+	dirs := []string{"/tmp"}
+	if tmp := os.TempDir(); tmp != "/tmp" {
+		dirs = append(dirs, tmp)
+	}
+	return dirs
+}
+
+// ResolvePath 展开~，返回基于cwd清理后的绝对路径
+// ~user 和cwd为空时的相对路径在运行前无法确定，返回false
+func ResolvePath(p, cwd string) (string, bool) {
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		p = home + p[1:]
+	} else if strings.HasPrefix(p, "~") {
+		return "", false
+	}
+	if !filepath.IsAbs(p) {
+		if cwd == "" {
+			return "", false
+		}
+		p = filepath.Join(cwd, p)
+	}
+	return filepath.Clean(p), true
+}
+
+// RealPath 解析绝对路径中的符号链接
+// 文件不存在时（如Write新文件）解析最近的已存在的上级目录，都不存在时原样返回
+func RealPath(p string) string {
+	var rest []string
+	for dir := p; ; dir = filepath.Dir(dir) {
+		if real, err := filepath.EvalSymlinks(dir); err == nil {
+			return filepath.Join(append([]string{real}, rest...)...)
+		}
+		if filepath.Dir(dir) == dir {
+			return p
+		}
+		rest = append([]string{filepath.Base(dir)}, rest...)
+	}
+}
@@ -0,0 +1,61 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolvePath(t *testing.T) {
+	t.Setenv("HOME", "/home/user")
+	tests := []struct {
+		path, cwd string
+		want      string
+		ok        bool
+	}{
+		{"/etc/../etc/hosts", "", "/etc/hosts", true},
+		{"build/../out", "/project", "/project/out", true},
+		{"~", "", "/home/user", true},
+		{"~/.profile", "/project", "/home/user/.profile", true},
+		{"~root/.profile", "/project", "", false},
+		{"out", "", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ResolvePath(tt.path, tt.cwd)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ResolvePath(%q, %q) = %q, %v, want %q, %v", tt.path, tt.cwd, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRealPath(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "target"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("target", filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	// 不存在的文件和目录解析到最近的已存在的上级目录
+	if got, want := RealPath(filepath.Join(dir, "link", "new", "file.go")), filepath.Join(dir, "target", "new", "file.go"); got != want {
+		t.Errorf("RealPath = %q, want %q", got, want)
+	}
+	if got := RealPath(filepath.Join(dir, "target")); got != filepath.Join(dir, "target") {
+		t.Errorf("RealPath of a real directory = %q", got)
+	}
+}
+
+func TestTempDirs(t *testing.T) {
+	t.Setenv("TMPDIR", "/var/tmp/session")
+	if got := TempDirs(); len(got) != 2 || got[0] != "/tmp" || got[1] != "/var/tmp/session" {
+		t.Errorf("TempDirs() = %v", got)
+	}
+	t.Setenv("TMPDIR", "/tmp")
+	if got := TempDirs(); len(got) != 1 {
+		t.Errorf("TempDirs() with TMPDIR=/tmp = %v", got)
+	}
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,12 +10,26 @@ import (
 	"sync"
 )
 
+// APIVersion 插件接口版本，插件接口发生不兼容变更时递增
+const APIVersion = 2
+
 type PluginMetadata struct {
 	Description string
-	Matcher     struct {
+	// Deprecated: 使用Matchers，可以为任意事件声明多个匹配器
+	Matcher struct {
 		PreToolUse  string
 		PostToolUse string
 	}
+	// 插件声明的匹配器，可以为同一事件声明多个
+	Matchers []Matcher
+	// 插件自身的版本号
+	Version string
+	// 插件编译时的types.APIVersion，为0表示未声明
+	APIVersion int
+	// 插件运行时依赖的外部命令，如goimports、gopls
+	Requires []string
+	// 插件配置的JSON Schema，没有配置时为空
+	ConfigSchema json.RawMessage
 }
 
 type PluginInfo struct {
@@ -23,51 +38,108 @@ type PluginInfo struct {
 	Description string
 }
 
+// IPlugin 所有插件都必须实现的基础接口
+// 插件通过额外实现PreToolUseHandler等事件接口声明自己处理哪些hook事件
 type IPlugin interface {
 	Initialize() error
 	Cleanup() error
 	GetMetadata() PluginMetadata
+}
+
+// PreToolUseHandler 处理PreToolUse事件的插件
+type PreToolUseHandler interface {
 	PreToolUse(arg ToolInput) (*PreToolUseOutput, error)
+}
+
+// PostToolUseHandler 处理PostToolUse事件的插件
+type PostToolUseHandler interface {
 	PostToolUse(arg PostToolUseInput) (*PostToolUseOutput, error)
+}
+
+// NotificationHandler 处理Notification事件的插件
+type NotificationHandler interface {
 	Notification(arg NotificationInput) (*BaseHookOutput, error)
-	Stop(arg StopInput) (*StopOutput, error)
-	SubagentStop(arg SubagentStopInput) (*DecisionOutput, error)
 }
 
-type UnimplementedPlugin struct{}
+// StopHandler 处理Stop事件的插件
+type StopHandler interface {
+	Stop(arg StopInput) (*StopOutput, error)
+}
 
-func (u UnimplementedPlugin) Initialize() error {
-	return nil
+// SubagentStopHandler 处理SubagentStop事件的插件
+type SubagentStopHandler interface {
+	SubagentStop(arg SubagentStopInput) (*DecisionOutput, error)
 }
 
-func (u UnimplementedPlugin) Cleanup() error {
-	return nil
+// PreCompactHandler 处理PreCompact事件的插件
+type PreCompactHandler interface {
+	PreCompact(arg PreCompactInput) (*BaseHookOutput, error)
 }
 
-func (u UnimplementedPlugin) PreToolUse(arg ToolInput) (*PreToolUseOutput, error) {
-	panic("implement me")
+// SessionStartHandler 处理SessionStart事件的插件
+type SessionStartHandler interface {
+	SessionStart(arg SessionStartInput) (*BaseHookOutput, error)
 }
 
-func (u UnimplementedPlugin) PostToolUse(arg PostToolUseInput) (*PostToolUseOutput, error) {
-	panic("implement me")
+// HookEvents 支持的hook事件，按Claude Code文档中的顺序排列
+var HookEvents = []string{"PreToolUse", "PostToolUse", "Notification", "Stop", "SubagentStop", "PreCompact", "SessionStart"}
+
+// Implements 判断插件是否处理指定的hook事件
+func Implements(plugin IPlugin, event string) bool {
+	switch event {
+	case "PreToolUse":
+		_, ok := plugin.(PreToolUseHandler)
+		return ok
+	case "PostToolUse":
+		_, ok := plugin.(PostToolUseHandler)
+		return ok
+	case "Notification":
+		_, ok := plugin.(NotificationHandler)
+		return ok
+	case "Stop":
+		_, ok := plugin.(StopHandler)
+		return ok
+	case "SubagentStop":
+		_, ok := plugin.(SubagentStopHandler)
+		return ok
+	case "PreCompact":
+		_, ok := plugin.(PreCompactHandler)
+		return ok
+	case "SessionStart":
+		_, ok := plugin.(SessionStartHandler)
+		return ok
+	default:
+		return false
+	}
 }
 
-func (u UnimplementedPlugin) Notification(arg NotificationInput) (*BaseHookOutput, error) {
-	panic("implement me")
+// ImplementedEvents 返回插件处理的所有hook事件
+func ImplementedEvents(plugin IPlugin) []string {
+	events := make([]string, 0, len(HookEvents))
+	for _, event := range HookEvents {
+		if Implements(plugin, event) {
+			events = append(events, event)
+		}
+	}
+	return events
 }
 
-func (u UnimplementedPlugin) Stop(arg StopInput) (*StopOutput, error) {
-	panic("implement me")
+// UnimplementedPlugin 提供Initialize和Cleanup的空实现，嵌入后只需实现需要的事件方法
+type UnimplementedPlugin struct{}
+
+func (u UnimplementedPlugin) Initialize() error {
+	return nil
 }
 
-func (u UnimplementedPlugin) SubagentStop(arg SubagentStopInput) (*DecisionOutput, error) {
-	panic("implement me")
+func (u UnimplementedPlugin) Cleanup() error {
+	return nil
 }
 
 // PluginManager 插件管理器
 type PluginManager struct {
 	plugins     map[string]IPlugin
 	pluginPaths map[string]string // 存储插件名称到路径的映射
+	order       []string          // 插件的加载顺序，hook按此顺序执行
 	pluginDir   string
 	mu          sync.RWMutex
 }
@@ -115,6 +187,16 @@ func (pm *PluginManager) LoadPlugin(pluginPath string) error {
 		return fmt.Errorf("plugin %s New function returned nil", pluginPath)
 	}
 
+	// 检查接口版本
+	if v := pluginInstance.GetMetadata().APIVersion; v != 0 && v != APIVersion {
+		return fmt.Errorf("plugin %s was built for API version %d, host supports API version %d", pluginPath, v, APIVersion)
+	}
+
+	// 加载插件配置
+	if err := ConfigurePlugin(pluginInstance, pluginPath); err != nil {
+		return fmt.Errorf("failed to configure plugin %s: %v", pluginPath, err)
+	}
+
 	// 初始化插件
 	if err := pluginInstance.Initialize(); err != nil {
 		return fmt.Errorf("failed to initialize plugin %s: %v", pluginPath, err)
@@ -125,9 +207,12 @@ func (pm *PluginManager) LoadPlugin(pluginPath string) error {
 	if err != nil {
 		absPath = pluginPath // 如果无法获取绝对路径，使用原路径
 	}
-	
+
 	// 注册插件
 	pluginName := filepath.Base(pluginPath)
+	if _, exists := pm.plugins[pluginName]; !exists {
+		pm.order = append(pm.order, pluginName)
+	}
 	pm.plugins[pluginName] = pluginInstance
 	pm.pluginPaths[pluginName] = absPath
 
@@ -183,6 +268,12 @@ func (pm *PluginManager) UnloadPlugin(name string) error {
 	// 从管理器中移除
 	delete(pm.plugins, name)
 	delete(pm.pluginPaths, name)
+	for i, n := range pm.order {
+		if n == name {
+			pm.order = append(pm.order[:i], pm.order[i+1:]...)
+			break
+		}
+	}
 
 	return nil
 }
@@ -196,14 +287,38 @@ func (pm *PluginManager) GetPlugin(name string) (IPlugin, bool) {
 	return pluginInstance, exists
 }
 
+// Plugins 按加载顺序返回所有插件
 func (pm *PluginManager) Plugins() []IPlugin {
-	var ret = make([]IPlugin, 0)
-	for _, p := range pm.plugins {
-		ret = append(ret, p)
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	var ret = make([]IPlugin, 0, len(pm.order))
+	for _, name := range pm.order {
+		ret = append(ret, pm.plugins[name])
 	}
 	return ret
 }
 
+// RunHook 按加载顺序执行插件，结果中的插件名为插件文件名
+func (pm *PluginManager) RunHook(data []byte) (*HookResult, error) {
+	pm.mu.RLock()
+	named := make([]namedPlugin, 0, len(pm.order))
+	for _, name := range pm.order {
+		named = append(named, namedPlugin{name: name, plugin: pm.plugins[name]})
+	}
+	pm.mu.RUnlock()
+
+	return runHook(named, data)
+}
+
+// PluginName 返回插件名称，插件实现了Name() string时使用其返回值，否则使用类型名
+func PluginName(plugin IPlugin) string {
+	if named, ok := plugin.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", plugin), "*")
+}
+
 // ListPlugins 列出所有已加载的插件
 func (pm *PluginManager) ListPlugins() []PluginInfo {
 	pm.mu.RLock()
@@ -236,6 +351,7 @@ func (pm *PluginManager) Shutdown() error {
 	// 清空插件映射
 	pm.plugins = make(map[string]IPlugin)
 	pm.pluginPaths = make(map[string]string)
+	pm.order = nil
 
 	if len(errors) > 0 {
 		return fmt.Errorf("shutdown errors: %s", strings.Join(errors, "; "))
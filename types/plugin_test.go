@@ -0,0 +1,146 @@
+package types
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type preToolUsePlugin struct {
+	UnimplementedPlugin
+	calls int
+}
+
+func (p *preToolUsePlugin) GetMetadata() PluginMetadata {
+	return PluginMetadata{}
+}
+
+func (p *preToolUsePlugin) PreToolUse(arg ToolInput) (*PreToolUseOutput, error) {
+	p.calls++
+	return nil, nil
+}
+
+type stopPlugin struct {
+	UnimplementedPlugin
+}
+
+func (p *stopPlugin) GetMetadata() PluginMetadata {
+	return PluginMetadata{}
+}
+
+func (p *stopPlugin) Stop(arg StopInput) (*StopOutput, error) {
+	var ret StopOutput
+	ret.NotAllowed("keep going")
+	return &ret, nil
+}
+
+func TestImplementedEvents(t *testing.T) {
+	if got, want := ImplementedEvents(&preToolUsePlugin{}), []string{"PreToolUse"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ImplementedEvents = %v, want %v", got, want)
+	}
+	if got, want := ImplementedEvents(&stopPlugin{}), []string{"Stop"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ImplementedEvents = %v, want %v", got, want)
+	}
+	if Implements(&stopPlugin{}, "Unknown") {
+		t.Error("Implements returned true for an unknown event")
+	}
+}
+
+func TestExecuteHookSkipsUnimplementedEvents(t *testing.T) {
+	pre := &preToolUsePlugin{}
+	plugins := []IPlugin{pre, &stopPlugin{}}
+
+	result, err := ExecuteHook(plugins, []byte(`{"hook_event_name":"Stop"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != ExitCodeBlockingError {
+		t.Errorf("Stop result code = %d, want %d", result.Code, ExitCodeBlockingError)
+	}
+
+	result, err = ExecuteHook(plugins, []byte(`{"hook_event_name":"PreToolUse","tool_name":"Bash"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsSuccess() || pre.calls != 1 {
+		t.Errorf("PreToolUse result = %+v, calls = %d", result, pre.calls)
+	}
+
+	result, err = ExecuteHook(plugins, []byte(`{"hook_event_name":"Notification","message":"hi"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsSuccess() || result.Data != "" {
+		t.Errorf("Notification result = %+v, want empty success", result)
+	}
+}
+
+// rewritePlugin 修改Bash命令，并记录收到的命令
+type rewritePlugin struct {
+	UnimplementedPlugin
+	rewrite func(command string) string
+	seen    []string
+}
+
+func (p *rewritePlugin) GetMetadata() PluginMetadata {
+	return PluginMetadata{Matchers: []Matcher{OnPreToolUse("Bash")}}
+}
+
+func (p *rewritePlugin) PreToolUse(arg ToolInput) (*PreToolUseOutput, error) {
+	bash, err := arg.AsBash()
+	if err != nil {
+		return nil, err
+	}
+	p.seen = append(p.seen, bash.Command)
+	if p.rewrite == nil {
+		return nil, nil
+	}
+
+	var ret PreToolUseOutput
+	return ret.UpdateInput(arg.Rewrite().Set("command", p.rewrite(bash.Command))), nil
+}
+
+func TestExecuteHookComposesUpdatedInput(t *testing.T) {
+	count := &rewritePlugin{rewrite: func(c string) string {
+		return strings.Replace(c, "go test", "go test -count=1", 1)
+	}}
+	noVerify := &rewritePlugin{rewrite: func(c string) string {
+		return strings.TrimSpace(strings.ReplaceAll(c, "--no-verify", ""))
+	}}
+	observer := &rewritePlugin{}
+	plugins := []IPlugin{count, noVerify, observer}
+
+	input := `{"hook_event_name":"PreToolUse","tool_name":"Bash","tool_input":{"command":"go test ./... --no-verify","timeout":1000}}`
+	result, err := RunHook(plugins, []byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code() != ExitCodeSuccess {
+		t.Fatalf("result = %+v", result)
+	}
+
+	if got, want := noVerify.seen, []string{"go test -count=1 ./... --no-verify"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("second plugin saw %v, want %v", got, want)
+	}
+	if got, want := observer.seen, []string{"go test -count=1 ./..."}; !reflect.DeepEqual(got, want) {
+		t.Errorf("third plugin saw %v, want %v", got, want)
+	}
+
+	want := map[string]any{"command": "go test -count=1 ./...", "timeout": float64(1000)}
+	if !reflect.DeepEqual(result.UpdatedInput, want) {
+		t.Errorf("updatedInput = %v, want %v", result.UpdatedInput, want)
+	}
+	if len(result.Plugins) != 3 || result.Plugins[2].UpdatedInput != nil {
+		t.Errorf("plugins = %+v", result.Plugins)
+	}
+}
+
+func TestUpdateInputTyped(t *testing.T) {
+	var ret PreToolUseOutput
+	ret.UpdateInput(WriteInput{FilePath: "/p/out.go", Content: "x"})
+
+	want := map[string]any{"file_path": "/p/out.go", "content": "x"}
+	if got := ret.UpdatedInput(); !reflect.DeepEqual(got, want) {
+		t.Errorf("updatedInput = %v, want %v", got, want)
+	}
+}
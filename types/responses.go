@@ -0,0 +1,195 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// BashResponse Bash工具的执行结果
+type BashResponse struct {
+	Stdout      string `json:"stdout"`
+	Stderr      string `json:"stderr"`
+	Interrupted bool   `json:"interrupted"`
+	IsImage     bool   `json:"isImage"`
+	// 退出码，旧版本的Claude Code不提供
+	ExitCode *int `json:"exitCode,omitempty"`
+	// 对非0退出码的解释，如grep没有匹配时返回1
+	ReturnCodeInterpretation string `json:"returnCodeInterpretation,omitempty"`
+}
+
+// PatchHunk 文件修改的差异块
+type PatchHunk struct {
+	OldStart int      `json:"oldStart"`
+	OldLines int      `json:"oldLines"`
+	NewStart int      `json:"newStart"`
+	NewLines int      `json:"newLines"`
+	Lines    []string `json:"lines"`
+}
+
+// WriteResponse Write工具的执行结果
+type WriteResponse struct {
+	// create 或 update
+	Type            string      `json:"type"`
+	FilePath        string      `json:"filePath"`
+	Content         string      `json:"content"`
+	StructuredPatch []PatchHunk `json:"structuredPatch,omitempty"`
+}
+
+// EditResponse Edit工具的执行结果
+type EditResponse struct {
+	FilePath        string      `json:"filePath"`
+	OldString       string      `json:"oldString"`
+	NewString       string      `json:"newString"`
+	OriginalFile    string      `json:"originalFile"`
+	StructuredPatch []PatchHunk `json:"structuredPatch,omitempty"`
+	UserModified    bool        `json:"userModified"`
+	ReplaceAll      bool        `json:"replaceAll"`
+}
+
+// MultiEditResponse MultiEdit工具的执行结果
+type MultiEditResponse struct {
+	FilePath             string          `json:"filePath"`
+	Edits                []EditOperation `json:"edits"`
+	OriginalFileContents string          `json:"originalFileContents"`
+	StructuredPatch      []PatchHunk     `json:"structuredPatch,omitempty"`
+	UserModified         bool            `json:"userModified"`
+}
+
+// ReadResponse Read工具的执行结果
+type ReadResponse struct {
+	// text、image、notebook 或 pdf
+	Type string `json:"type"`
+	File struct {
+		FilePath   string `json:"filePath"`
+		Content    string `json:"content"`
+		NumLines   int    `json:"numLines"`
+		StartLine  int    `json:"startLine"`
+		TotalLines int    `json:"totalLines"`
+	} `json:"file"`
+}
+
+// GlobResponse Glob工具的执行结果
+type GlobResponse struct {
+	Filenames  []string `json:"filenames"`
+	NumFiles   int      `json:"numFiles"`
+	DurationMs int      `json:"durationMs"`
+	Truncated  bool     `json:"truncated"`
+}
+
+// GrepResponse Grep工具的执行结果
+type GrepResponse struct {
+	Mode      string   `json:"mode"`
+	Filenames []string `json:"filenames"`
+	NumFiles  int      `json:"numFiles"`
+	Content   string   `json:"content,omitempty"`
+	NumLines  int      `json:"numLines,omitempty"`
+}
+
+// DecodeResponse 将原始工具结果解码到v中
+func (p *PostToolUseInput) DecodeResponse(v any) error {
+	data, err := json.Marshal(p.ToolResponse)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s response: %w", p.ToolName, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid %s response: %w", p.ToolName, err)
+	}
+	return nil
+}
+
+// decodeResponse 检查工具名后将结果解码为对应的类型
+func decodeResponse[T any](p *PostToolUseInput, toolName string) (*T, error) {
+	if p.ToolName != toolName {
+		return nil, fmt.Errorf("tool is %s, not %s", p.ToolName, toolName)
+	}
+	var v T
+	if err := p.DecodeResponse(&v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (p *PostToolUseInput) AsBashResponse() (*BashResponse, error) {
+	return decodeResponse[BashResponse](p, "Bash")
+}
+
+func (p *PostToolUseInput) AsWriteResponse() (*WriteResponse, error) {
+	return decodeResponse[WriteResponse](p, "Write")
+}
+
+func (p *PostToolUseInput) AsEditResponse() (*EditResponse, error) {
+	return decodeResponse[EditResponse](p, "Edit")
+}
+
+func (p *PostToolUseInput) AsMultiEditResponse() (*MultiEditResponse, error) {
+	return decodeResponse[MultiEditResponse](p, "MultiEdit")
+}
+
+func (p *PostToolUseInput) AsReadResponse() (*ReadResponse, error) {
+	return decodeResponse[ReadResponse](p, "Read")
+}
+
+func (p *PostToolUseInput) AsGlobResponse() (*GlobResponse, error) {
+	return decodeResponse[GlobResponse](p, "Glob")
+}
+
+func (p *PostToolUseInput) AsGrepResponse() (*GrepResponse, error) {
+	return decodeResponse[GrepResponse](p, "Grep")
+}
+
+// Succeeded 判断工具是否成功执行
+//   - 结果中有success字段时以其为准，有非空的error字段时视为失败
+//   - Bash: 未被中断且退出码为0（没有退出码时只检查是否中断）
+//   - 其他工具: 有结果即视为成功
+func (p *PostToolUseInput) Succeeded() bool {
+	if success, ok := p.ToolResponse["success"].(bool); ok {
+		return success
+	}
+	if msg, ok := p.ToolResponse["error"].(string); ok && msg != "" {
+		return false
+	}
+
+	if p.ToolName == "Bash" {
+		bash, err := p.AsBashResponse()
+		if err != nil {
+			return false
+		}
+		return !bash.Interrupted && (bash.ExitCode == nil || *bash.ExitCode == 0)
+	}
+
+	return p.ToolResponse != nil
+}
+
+// Stdout 返回Bash命令的标准输出，其他工具返回空字符串
+func (p *PostToolUseInput) Stdout() string {
+	stdout, _ := p.ToolResponse["stdout"].(string)
+	return stdout
+}
+
+// Stderr 返回Bash命令的标准错误，其他工具返回空字符串
+func (p *PostToolUseInput) Stderr() string {
+	stderr, _ := p.ToolResponse["stderr"].(string)
+	return stderr
+}
+
+// AffectedFiles 返回本次调用实际修改的文件，工具未成功执行或不修改文件时返回nil
+func (p *PostToolUseInput) AffectedFiles() []string {
+	if !p.Succeeded() {
+		return nil
+	}
+
+	switch p.ToolName {
+	case "Write", "Edit", "MultiEdit":
+		if filePath, ok := p.ToolResponse["filePath"].(string); ok && filePath != "" {
+			return []string{filePath}
+		}
+		if filePath := p.GetFilePath(); filePath != "" {
+			return []string{filePath}
+		}
+	case "NotebookEdit":
+		if notebookPath, ok := p.ToolInput.ToolInput["notebook_path"].(string); ok && notebookPath != "" {
+			return []string{notebookPath}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func parsePostToolUseInput(t *testing.T, data string) *PostToolUseInput {
+	t.Helper()
+	var input PostToolUseInput
+	if err := json.Unmarshal([]byte(data), &input); err != nil {
+		t.Fatal(err)
+	}
+	return &input
+}
+
+func TestPostToolUseSucceeded(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want bool
+	}{
+		{"write success flag", `{"tool_name":"Write","tool_input":{"file_path":"/a.go"},"tool_response":{"filePath":"/a.go","success":true}}`, true},
+		{"write failure flag", `{"tool_name":"Write","tool_input":{"file_path":"/a.go"},"tool_response":{"filePath":"/a.go","success":false}}`, false},
+		{"edit applied", `{"tool_name":"Edit","tool_input":{"file_path":"/a.go"},"tool_response":{"filePath":"/a.go","oldString":"a","newString":"b"}}`, true},
+		{"error field", `{"tool_name":"Edit","tool_input":{"file_path":"/a.go"},"tool_response":{"error":"String to replace not found"}}`, false},
+		{"bash ok", `{"tool_name":"Bash","tool_input":{"command":"ls"},"tool_response":{"stdout":"a","stderr":"","interrupted":false}}`, true},
+		{"bash interrupted", `{"tool_name":"Bash","tool_input":{"command":"sleep 100"},"tool_response":{"stdout":"","stderr":"","interrupted":true}}`, false},
+		{"bash exit code", `{"tool_name":"Bash","tool_input":{"command":"false"},"tool_response":{"stdout":"","stderr":"","interrupted":false,"exitCode":1}}`, false},
+		{"missing response", `{"tool_name":"Write","tool_input":{"file_path":"/a.go"}}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parsePostToolUseInput(t, tt.data).Succeeded(); got != tt.want {
+				t.Errorf("Succeeded = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAffectedFiles(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{"edit", `{"tool_name":"Edit","tool_input":{"file_path":"/in.go"},"tool_response":{"filePath":"/out.go"}}`, []string{"/out.go"}},
+		{"write without response path", `{"tool_name":"Write","tool_input":{"file_path":"/in.go"},"tool_response":{"success":true}}`, []string{"/in.go"}},
+		{"failed write", `{"tool_name":"Write","tool_input":{"file_path":"/in.go"},"tool_response":{"success":false}}`, nil},
+		{"notebook", `{"tool_name":"NotebookEdit","tool_input":{"notebook_path":"/a.ipynb"},"tool_response":{}}`, []string{"/a.ipynb"}},
+		{"read", `{"tool_name":"Read","tool_input":{"file_path":"/in.go"},"tool_response":{"type":"text"}}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parsePostToolUseInput(t, tt.data).AffectedFiles(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AffectedFiles = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAsBashResponse(t *testing.T) {
+	input := parsePostToolUseInput(t, `{"tool_name":"Bash","tool_input":{"command":"go vet"},"tool_response":{"stdout":"out","stderr":"err","interrupted":false,"isImage":false}}`)
+
+	bash, err := input.AsBashResponse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bash.Stdout != "out" || bash.Stderr != "err" || bash.ExitCode != nil {
+		t.Errorf("AsBashResponse = %+v", bash)
+	}
+	if input.Stdout() != "out" || input.Stderr() != "err" {
+		t.Errorf("Stdout/Stderr = %q/%q", input.Stdout(), input.Stderr())
+	}
+	if _, err := input.AsEditResponse(); err == nil {
+		t.Error("AsEditResponse on a Bash response should fail")
+	}
+}
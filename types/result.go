@@ -0,0 +1,224 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Decision 插件对本次hook的决策
+type Decision string
+
+const (
+	// DecisionNone 没有决策，进入Claude Code现有的决策流程
+	DecisionNone Decision = ""
+	// DecisionApprove PreToolUse中跳过权限确认，reason显示给用户
+	DecisionApprove Decision = "approve"
+	// DecisionBlock 阻止工具调用或停止，reason显示给claude
+	DecisionBlock Decision = "block"
+	// DecisionAsk PreToolUse中要求用户确认工具调用，reason显示给用户
+	DecisionAsk Decision = "ask"
+)
+
+// HookResult 类型化的hook结果
+// 每个插件的执行结果和聚合后的结果都使用该类型，输出层据此生成JSON或退出码
+type HookResult struct {
+	// hook事件名
+	Event string
+	// 插件名，聚合结果为空
+	Plugin   string
+	Decision Decision
+	Reason   string
+	// 提供给claude的额外上下文，多个插件的上下文按顺序拼接
+	AdditionalContext string
+	// 为false时claude在hook运行后停止工作
+	Continue *bool
+	// Continue为false时显示给用户的消息
+	StopReason     string
+	SuppressOutput bool
+	// PreToolUse修改后的完整工具输入
+	UpdatedInput map[string]any
+	// 插件出错或输入无效，不影响claude
+	Err error
+	// 插件执行耗时，聚合结果为所有插件的耗时之和
+	Duration time.Duration
+	// 插件因匹配器不匹配而未被调用
+	Skipped bool
+	// 聚合结果中每个被执行插件的结果，按执行顺序排列
+	Plugins []*HookResult
+}
+
+// Blocked 判断结果是否为阻塞决策
+func (r *HookResult) Blocked() bool {
+	return r.Err == nil && r.Decision == DecisionBlock
+}
+
+// Code 返回结果对应的退出码
+func (r *HookResult) Code() int {
+	switch {
+	case r.Err != nil:
+		return ExitCodeError
+	case r.Decision == DecisionBlock:
+		return ExitCodeBlockingError
+	default:
+		return ExitCodeSuccess
+	}
+}
+
+// merge 将单个插件的结果合并到聚合结果
+//   - decision和reason取最后一个做出决策的插件，但approve不会覆盖ask
+//   - 任一插件continue为false时结果为false，stopReason取第一个
+//   - 上下文按顺序拼接，updatedInput已经按顺序叠加，取最后一个
+func (r *HookResult) merge(other *HookResult) {
+	r.Plugins = append(r.Plugins, other)
+	r.Duration += other.Duration
+	if other.Skipped {
+		return
+	}
+
+	if other.Err != nil {
+		r.Err = other.Err
+	}
+	if other.Decision != DecisionNone && !(r.Decision == DecisionAsk && other.Decision == DecisionApprove) {
+		r.Decision = other.Decision
+		r.Reason = other.Reason
+	}
+	if other.AdditionalContext != "" {
+		if r.AdditionalContext != "" {
+			r.AdditionalContext += "\n"
+		}
+		r.AdditionalContext += other.AdditionalContext
+	}
+	if other.Continue != nil && (r.Continue == nil || !*other.Continue) {
+		r.Continue = other.Continue
+	}
+	if r.StopReason == "" {
+		r.StopReason = other.StopReason
+	}
+	r.SuppressOutput = r.SuppressOutput || other.SuppressOutput
+	if other.UpdatedInput != nil {
+		r.UpdatedInput = other.UpdatedInput
+	}
+}
+
+// fromBaseOutput 将插件返回的BaseHookOutput转换为HookResult
+func fromBaseOutput(o *BaseHookOutput) *HookResult {
+	if o == nil {
+		return &HookResult{}
+	}
+	return &HookResult{
+		Continue:       o.Continue,
+		StopReason:     o.StopReason,
+		SuppressOutput: o.SuppressOutput,
+	}
+}
+
+// fromDecisionOutput 将插件返回的DecisionOutput转换为HookResult
+func fromDecisionOutput(o *DecisionOutput) *HookResult {
+	if o == nil {
+		return &HookResult{}
+	}
+	result := fromBaseOutput(&o.BaseHookOutput)
+	if o.Decision != nil {
+		result.Decision = Decision(*o.Decision)
+	}
+	if o.Reason != nil {
+		result.Reason = *o.Reason
+	}
+	return result
+}
+
+func fromPreToolUseOutput(o *PreToolUseOutput) *HookResult {
+	if o == nil {
+		return &HookResult{}
+	}
+	result := fromDecisionOutput(&o.DecisionOutput)
+	result.UpdatedInput = o.UpdatedInput()
+	if specific := o.HookSpecificOutput; specific != nil && specific.PermissionDecision != "" {
+		result.Decision = permissionDecisions[specific.PermissionDecision]
+		result.Reason = specific.PermissionDecisionReason
+	}
+	return result
+}
+
+func fromPostToolUseOutput(o *PostToolUseOutput) *HookResult {
+	if o == nil {
+		return &HookResult{}
+	}
+	result := fromDecisionOutput(&o.DecisionOutput)
+	if o.HookSpecificOutput != nil {
+		result.AdditionalContext = o.HookSpecificOutput.AdditionalContext
+	}
+	return result
+}
+
+// permissionDecisions hookSpecificOutput.permissionDecision与Decision的对应关系
+var permissionDecisions = map[string]Decision{
+	"allow": DecisionApprove,
+	"deny":  DecisionBlock,
+	"ask":   DecisionAsk,
+}
+
+// hookOutput Claude Code解析的JSON输出
+type hookOutput struct {
+	Continue           *bool               `json:"continue,omitempty"`
+	StopReason         string              `json:"stopReason,omitempty"`
+	SuppressOutput     bool                `json:"suppressOutput,omitempty"`
+	Decision           Decision            `json:"decision,omitempty"`
+	Reason             string              `json:"reason,omitempty"`
+	HookSpecificOutput *hookSpecificOutput `json:"hookSpecificOutput,omitempty"`
+}
+
+type hookSpecificOutput struct {
+	HookEventName            string         `json:"hookEventName"`
+	PermissionDecision       string         `json:"permissionDecision,omitempty"`
+	PermissionDecisionReason string         `json:"permissionDecisionReason,omitempty"`
+	AdditionalContext        string         `json:"additionalContext,omitempty"`
+	UpdatedInput             map[string]any `json:"updatedInput,omitempty"`
+}
+
+// JSON 返回Claude Code的JSON输出，结果中没有需要输出的字段时返回nil
+func (r *HookResult) JSON() ([]byte, error) {
+	out := hookOutput{
+		StopReason:     r.StopReason,
+		SuppressOutput: r.SuppressOutput,
+		Decision:       r.Decision,
+		Reason:         r.Reason,
+	}
+	if r.Continue != nil && !*r.Continue {
+		// continue为true是Claude Code的默认值，只输出false，没有其他字段时不输出任何内容
+		out.Continue = r.Continue
+	}
+	if r.Decision == DecisionAsk || r.AdditionalContext != "" || r.UpdatedInput != nil {
+		out.HookSpecificOutput = &hookSpecificOutput{
+			HookEventName:     r.Event,
+			AdditionalContext: r.AdditionalContext,
+			UpdatedInput:      r.UpdatedInput,
+		}
+	}
+	if r.Decision == DecisionAsk {
+		// 顶层的decision不支持ask，只能通过permissionDecision表达
+		out.Decision, out.Reason = DecisionNone, ""
+		out.HookSpecificOutput.PermissionDecision = "ask"
+		out.HookSpecificOutput.PermissionDecisionReason = r.Reason
+	}
+	if out == (hookOutput{}) {
+		return nil, nil
+	}
+	return json.Marshal(out)
+}
+
+// Result 转换为以退出码、错误和JSON数据表示的结果
+func (r *HookResult) Result() Result {
+	if r.Err != nil {
+		return NewError(r.Err.Error())
+	}
+
+	data, err := r.JSON()
+	if err != nil {
+		return NewError("failed to marshal result: " + err.Error())
+	}
+	if r.Blocked() {
+		return Result{Code: ExitCodeBlockingError, Error: r.Reason, Data: string(data)}
+	}
+	return NewSuccess(string(data))
+}
@@ -0,0 +1,189 @@
+package types
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// compactPlugin 返回固定的PreCompact结果
+type compactPlugin struct {
+	UnimplementedPlugin
+	name   string
+	output *BaseHookOutput
+	err    error
+}
+
+func (p *compactPlugin) Name() string {
+	return p.name
+}
+
+func (p *compactPlugin) GetMetadata() PluginMetadata {
+	return PluginMetadata{Matchers: []Matcher{OnPreCompact("manual")}}
+}
+
+func (p *compactPlugin) PreCompact(arg PreCompactInput) (*BaseHookOutput, error) {
+	return p.output, p.err
+}
+
+func TestRunHookMergesResults(t *testing.T) {
+	plugins := []IPlugin{
+		&compactPlugin{name: "a", output: &BaseHookOutput{Continue: ptr(true)}},
+		&compactPlugin{name: "b", output: &BaseHookOutput{Continue: ptr(false), StopReason: "first", SuppressOutput: true}},
+		&compactPlugin{name: "c", output: &BaseHookOutput{Continue: ptr(true), StopReason: "second"}},
+	}
+
+	result, err := RunHook(plugins, []byte(`{"hook_event_name":"PreCompact","trigger":"manual"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Continue == nil || *result.Continue {
+		t.Errorf("Continue = %v, want false", result.Continue)
+	}
+	if result.StopReason != "first" || !result.SuppressOutput {
+		t.Errorf("StopReason = %q, SuppressOutput = %v", result.StopReason, result.SuppressOutput)
+	}
+	if result.Code() != ExitCodeSuccess || result.Event != "PreCompact" {
+		t.Errorf("Code = %d, Event = %q", result.Code(), result.Event)
+	}
+
+	var names []string
+	for _, p := range result.Plugins {
+		names = append(names, p.Plugin)
+		if p.Event != "PreCompact" {
+			t.Errorf("plugin %s event = %q", p.Plugin, p.Event)
+		}
+	}
+	if len(names) != 3 || names[0] != "a" || names[2] != "c" {
+		t.Errorf("plugins = %v", names)
+	}
+}
+
+func TestRunHookStopsOnError(t *testing.T) {
+	last := &compactPlugin{name: "last"}
+	plugins := []IPlugin{
+		&compactPlugin{name: "broken", err: errors.New("boom")},
+		last,
+	}
+
+	result, err := RunHook(plugins, []byte(`{"hook_event_name":"PreCompact","trigger":"manual"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code() != ExitCodeError || result.Err.Error() != "boom" {
+		t.Errorf("result = %+v", result)
+	}
+	if len(result.Plugins) != 1 || result.Plugins[0].Plugin != "broken" {
+		t.Errorf("plugins = %+v", result.Plugins)
+	}
+}
+
+func TestRunHookSkippedPlugin(t *testing.T) {
+	plugin := &compactPlugin{name: "manual-only", output: &BaseHookOutput{Continue: ptr(false)}}
+
+	result, err := RunHook([]IPlugin{plugin}, []byte(`{"hook_event_name":"PreCompact","trigger":"auto"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Plugins) != 1 || !result.Plugins[0].Skipped {
+		t.Fatalf("plugins = %+v", result.Plugins)
+	}
+	if result.Continue != nil {
+		t.Errorf("Continue = %v, want unset for skipped plugin", *result.Continue)
+	}
+	if data, err := result.JSON(); err != nil || data != nil {
+		t.Errorf("JSON = %s, %v, want no output", data, err)
+	}
+}
+
+// askPlugin 要求用户确认Bash命令
+type askPlugin struct {
+	UnimplementedPlugin
+}
+
+func (p *askPlugin) GetMetadata() PluginMetadata {
+	return PluginMetadata{Matchers: []Matcher{OnPreToolUse("Bash")}}
+}
+
+func (p *askPlugin) PreToolUse(arg ToolInput) (*PreToolUseOutput, error) {
+	var ret PreToolUseOutput
+	return ret.Ask("confirm " + arg.ToolInput["command"].(string)), nil
+}
+
+// approvePlugin 批准所有工具调用
+type approvePlugin struct {
+	UnimplementedPlugin
+}
+
+func (p *approvePlugin) GetMetadata() PluginMetadata {
+	return PluginMetadata{Matchers: []Matcher{OnPreToolUse("*")}}
+}
+
+func (p *approvePlugin) PreToolUse(arg ToolInput) (*PreToolUseOutput, error) {
+	var ret PreToolUseOutput
+	return ret.Approve(true, "fine"), nil
+}
+
+func TestRunHookAsk(t *testing.T) {
+	data := []byte(`{"hook_event_name":"PreToolUse","tool_name":"Bash","tool_input":{"command":"make deploy"}}`)
+
+	// approve不会覆盖之前的ask
+	result, err := RunHook([]IPlugin{&askPlugin{}, &approvePlugin{}}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Decision != DecisionAsk || result.Reason != "confirm make deploy" || result.Code() != ExitCodeSuccess {
+		t.Fatalf("result = %+v", result)
+	}
+
+	out, err := result.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"hookSpecificOutput":{"hookEventName":"PreToolUse","permissionDecision":"ask","permissionDecisionReason":"confirm make deploy"}}`
+	if string(out) != want {
+		t.Errorf("JSON = %s, want %s", out, want)
+	}
+
+	var stdout, stderr strings.Builder
+	if code := result.Write(OutputModeExitCode, &stdout, &stderr); code != ExitCodeBlockingError || stderr.String() != "confirm make deploy\n" {
+		t.Errorf("exitcode mode = %d, stderr %q", code, stderr.String())
+	}
+}
+
+// contextPlugin 在PostToolUse中提供额外的上下文
+type contextPlugin struct {
+	UnimplementedPlugin
+	context string
+}
+
+func (p *contextPlugin) GetMetadata() PluginMetadata {
+	return PluginMetadata{Matchers: []Matcher{OnPostToolUse("*")}}
+}
+
+func (p *contextPlugin) PostToolUse(arg PostToolUseInput) (*PostToolUseOutput, error) {
+	var ret PostToolUseOutput
+	return ret.AddContext(p.context), nil
+}
+
+func TestRunHookAdditionalContext(t *testing.T) {
+	data := []byte(`{"hook_event_name":"PostToolUse","tool_name":"Write","tool_input":{"file_path":"/p/a.go"}}`)
+
+	result, err := RunHook([]IPlugin{&contextPlugin{context: "first"}, &contextPlugin{context: "second"}}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.AdditionalContext != "first\nsecond" || result.Decision != DecisionNone || result.Code() != ExitCodeSuccess {
+		t.Fatalf("result = %+v", result)
+	}
+
+	out, err := result.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `"hookSpecificOutput":{"hookEventName":"PostToolUse","additionalContext":"first\nsecond"}`
+	if !strings.Contains(string(out), want) {
+		t.Errorf("JSON = %s, want it to contain %s", out, want)
+	}
+}
@@ -0,0 +1,622 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ShellCommand Bash命令中的一条简单命令
+type ShellCommand struct {
+	// 命令名和参数，引号和转义已去除
+	Args []string
+	// 命令前的变量赋值，如 FOO=bar
+	Assignments []string
+	Redirects   []Redirect
+	// 命令后的控制操作符：|、|&、||、&&、;、&，以换行、括号或输入结尾结束时为空
+	Op string
+}
+
+// Piped 判断命令的输出是否通过管道传给下一条命令
+func (c ShellCommand) Piped() bool {
+	return c.Op == "|" || c.Op == "|&"
+}
+
+// Redirect 重定向
+type Redirect struct {
+	// 重定向操作符，包括文件描述符，如 >、>>、2>、&>、<、<<、<<<、2>&
+	Op string
+	// 目标文件、文件描述符或here document的结束标记
+	Target string
+}
+
+// IsFile 判断重定向的目标是否为文件
+func (r Redirect) IsFile() bool {
+	switch {
+	case strings.HasPrefix(r.Op, "<<"):
+		return false
+	case strings.HasSuffix(r.Op, "&"):
+		// 2>&1、<&0、>&- 等复制或关闭文件描述符
+		return strings.Trim(r.Target, "0123456789-") != ""
+	default:
+		return true
+	}
+}
+
+// Name 返回命令名（不含路径），没有命令时返回空字符串
+func (c ShellCommand) Name() string {
+	if len(c.Args) == 0 {
+		return ""
+	}
+	return filepath.Base(c.Args[0])
+}
+
+// wrapperOptions 包装命令及其需要参数值的选项，Unwrap会跳过这些包装命令
+var wrapperOptions = map[string]map[string]bool{
+	"sudo":    {"-u": true, "-g": true, "-C": true, "-h": true, "-p": true, "-U": true},
+	"doas":    {"-u": true, "-C": true},
+	"env":     {"-u": true, "-C": true, "-S": true},
+	"nohup":   {},
+	"time":    {"-o": true, "-f": true},
+	"nice":    {"-n": true},
+	"ionice":  {"-c": true, "-n": true, "-p": true},
+	"stdbuf":  {"-i": true, "-o": true, "-e": true},
+	"timeout": {"-s": true, "-k": true},
+	"xargs":   {"-I": true, "-n": true, "-P": true, "-L": true, "-d": true, "-E": true, "-s": true, "-a": true},
+	"command": {},
+	"exec":    {"-a": true},
+	"builtin": {},
+}
+
+// Unwrap 去除sudo、env、xargs、timeout等包装命令，返回实际执行的命令
+// 如 "sudo -u root rm -rf /tmp/x" 返回 "rm -rf /tmp/x"
+func (c ShellCommand) Unwrap() ShellCommand {
+	args := c.Args
+	for len(args) > 0 {
+		name := filepath.Base(args[0])
+		options, ok := wrapperOptions[name]
+		if !ok {
+			break
+		}
+		args = args[1:]
+
+		for len(args) > 0 {
+			arg := args[0]
+			if arg == "--" {
+				args = args[1:]
+				break
+			}
+			if strings.HasPrefix(arg, "-") && len(arg) > 1 {
+				args = args[1:]
+				if options[arg] && len(args) > 0 {
+					args = args[1:]
+				}
+				continue
+			}
+			if name == "env" && isAssignment(arg) {
+				args = args[1:]
+				continue
+			}
+			if name == "timeout" {
+				// 超时时间
+				args = args[1:]
+			}
+			break
+		}
+	}
+
+	unwrapped := c
+	unwrapped.Args = args
+	return unwrapped
+}
+
+// DirTarget 返回cd或pushd切换到的目录，c应先Unwrap；不是cd或pushd时返回false
+// 没有参数时为~；cd - 返回"-"，回到的目录在运行前无法确定
+func (c ShellCommand) DirTarget() (string, bool) {
+	if name := c.Name(); name != "cd" && name != "pushd" {
+		return "", false
+	}
+	for _, arg := range c.Args[1:] {
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			return arg, true
+		}
+	}
+	return "~", true
+}
+
+// shellNames 支持 -c 参数执行脚本的shell
+var shellNames = map[string]bool{"sh": true, "bash": true, "zsh": true, "dash": true, "ksh": true}
+
+// script 返回 bash -c '...' 或 eval '...' 执行的脚本
+func (c ShellCommand) script() (string, bool) {
+	name := c.Name()
+	if name == "eval" && len(c.Args) > 1 {
+		return strings.Join(c.Args[1:], " "), true
+	}
+	if !shellNames[name] {
+		return "", false
+	}
+	for i := 1; i < len(c.Args); i++ {
+		arg := c.Args[i]
+		if !strings.HasPrefix(arg, "-") {
+			return "", false
+		}
+		if strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "--") && strings.Contains(arg, "c") && i+1 < len(c.Args) {
+			return c.Args[i+1], true
+		}
+	}
+	return "", false
+}
+
+// shellKeywords 出现在命令开头的保留字，解析时跳过
+var shellKeywords = map[string]bool{
+	"if": true, "then": true, "else": true, "elif": true, "fi": true,
+	"for": true, "while": true, "until": true, "do": true, "done": true,
+	"case": true, "esac": true, "!": true, "{": true, "}": true,
+}
+
+var assignmentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\[[^]]*\])?\+?=`)
+
+func isAssignment(word string) bool {
+	return assignmentPattern.MatchString(word)
+}
+
+// maxShellDepth 命令替换和 bash -c 的最大嵌套深度
+const maxShellDepth = 16
+
+// variablePattern shell变量引用，如 $HOME、${HOME}
+var variablePattern = regexp.MustCompile(`\$(?:\{([A-Za-z_][A-Za-z0-9_]*)\}|([A-Za-z_][A-Za-z0-9_]*))`)
+
+// ExpandVariables 展开ParseShell得到的单词中的$NAME和${NAME}
+// vars为命令中赋值的变量，优先于环境变量；$PWD为cwd，cwd为空表示工作目录未知。
+// 单词包含命令替换、进程替换、未定义的变量或其他无法展开的$时返回false
+func ExpandVariables(word, cwd string, vars map[string]string) (string, bool) {
+	if strings.Contains(word, "`") || strings.Contains(word, "$(") || strings.Contains(word, "<(") {
+		return "", false
+	}
+	resolved := true
+	word = variablePattern.ReplaceAllStringFunc(word, func(ref string) string {
+		name := strings.Trim(ref, "${}")
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		if name == "PWD" {
+			resolved = resolved && cwd != ""
+			return cwd
+		}
+		value, ok := os.LookupEnv(name)
+		resolved = resolved && ok
+		return value
+	})
+	return word, resolved && !strings.Contains(word, "$")
+}
+
+// ParseShell 将Bash命令解析为简单命令
+//
+// 支持引号和转义、管道和命令列表（| || && ; & 换行）、子shell、命令替换 $(...) 和 `...`、
+// 进程替换、重定向和here document；bash -c 和 eval 的脚本会被递归解析。
+// 命令替换等嵌套的命令排在所属命令之后。变量和通配符不会展开。
+func ParseShell(command string) ([]ShellCommand, error) {
+	p := &shellParser{src: command}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.commands, nil
+}
+
+type shellParser struct {
+	src      string
+	pos      int
+	depth    int
+	commands []ShellCommand
+	// 当前命令中命令替换解析出的命令
+	nested []ShellCommand
+	// 等待读取正文的here document结束标记
+	heredocs []string
+}
+
+func (p *shellParser) parse() error {
+	var cur ShellCommand
+	flush := func() error {
+		if len(cur.Args) > 0 || len(cur.Assignments) > 0 || len(cur.Redirects) > 0 {
+			p.commands = append(p.commands, cur)
+			if script, ok := cur.Unwrap().script(); ok {
+				inner, err := p.parseNested(script)
+				if err != nil {
+					return err
+				}
+				p.commands = append(p.commands, inner...)
+			}
+		}
+		p.commands = append(p.commands, p.nested...)
+		p.nested = nil
+		cur = ShellCommand{}
+		return nil
+	}
+
+	for {
+		p.skipBlanks()
+		if p.pos >= len(p.src) {
+			if len(p.heredocs) > 0 {
+				return fmt.Errorf("unterminated here document %q", p.heredocs[0])
+			}
+			return flush()
+		}
+
+		c := p.src[p.pos]
+		switch {
+		case c == '#':
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+
+		case c == '\n':
+			p.pos++
+			if err := flush(); err != nil {
+				return err
+			}
+			if err := p.skipHeredocs(); err != nil {
+				return err
+			}
+
+		case p.atRedirect():
+			op := p.readRedirectOp()
+			p.skipBlanks()
+			if p.pos >= len(p.src) || strings.ContainsRune(";|&()<>\n", rune(p.src[p.pos])) {
+				return fmt.Errorf("missing target for redirection %s", op)
+			}
+			target, err := p.readWord()
+			if err != nil {
+				return err
+			}
+			if heredoc := strings.TrimLeft(op, "0123456789"); heredoc == "<<" || heredoc == "<<-" {
+				p.heredocs = append(p.heredocs, target)
+			}
+			cur.Redirects = append(cur.Redirects, Redirect{Op: op, Target: target})
+
+		case strings.ContainsRune(";|&()", rune(c)):
+			start := p.pos
+			p.pos++
+			// || && ;; |& 视为一个操作符
+			if p.pos < len(p.src) && (p.src[p.pos] == c || (c == '|' && p.src[p.pos] == '&')) && c != '(' && c != ')' {
+				p.pos++
+			}
+			if c != '(' && c != ')' {
+				cur.Op = p.src[start:p.pos]
+			}
+			if err := flush(); err != nil {
+				return err
+			}
+
+		default:
+			word, err := p.readWord()
+			if err != nil {
+				return err
+			}
+			switch {
+			case len(cur.Args) == 0 && isAssignment(word):
+				cur.Assignments = append(cur.Assignments, word)
+			case len(cur.Args) == 0 && shellKeywords[word]:
+			default:
+				cur.Args = append(cur.Args, word)
+			}
+		}
+	}
+}
+
+func (p *shellParser) parseNested(src string) ([]ShellCommand, error) {
+	if p.depth >= maxShellDepth {
+		return nil, errors.New("shell command nested too deeply")
+	}
+	inner := &shellParser{src: src, depth: p.depth + 1}
+	if err := inner.parse(); err != nil {
+		return nil, err
+	}
+	return inner.commands, nil
+}
+
+func (p *shellParser) skipBlanks() {
+	for p.pos < len(p.src) {
+		switch {
+		case p.src[p.pos] == ' ' || p.src[p.pos] == '\t' || p.src[p.pos] == '\r':
+			p.pos++
+		case strings.HasPrefix(p.src[p.pos:], "\\\n"):
+			p.pos += 2
+		default:
+			return
+		}
+	}
+}
+
+// skipHeredocs 跳过here document的正文
+func (p *shellParser) skipHeredocs() error {
+	for _, delimiter := range p.heredocs {
+		for found := false; !found; {
+			if p.pos >= len(p.src) {
+				return fmt.Errorf("unterminated here document %q", delimiter)
+			}
+			line := p.src[p.pos:]
+			if end := strings.IndexByte(line, '\n'); end >= 0 {
+				line = line[:end]
+				p.pos += end + 1
+			} else {
+				p.pos = len(p.src)
+			}
+			found = strings.TrimLeft(line, "\t") == delimiter
+		}
+	}
+	p.heredocs = nil
+	return nil
+}
+
+var redirectPattern = regexp.MustCompile(`^(?:[0-9]*(?:<<<|<<-|<<|<>|<&|<|>>|>&|>\||>)|&>>|&>)`)
+
+func (p *shellParser) atRedirect() bool {
+	rest := p.src[p.pos:]
+	// <(...) 和 >(...) 是进程替换
+	if strings.HasPrefix(rest, "<(") || strings.HasPrefix(rest, ">(") {
+		return false
+	}
+	return redirectPattern.MatchString(rest)
+}
+
+func (p *shellParser) readRedirectOp() string {
+	op := redirectPattern.FindString(p.src[p.pos:])
+	p.pos += len(op)
+	return op
+}
+
+// readWord 读取一个单词，去除引号和转义，并解析其中的命令替换
+func (p *shellParser) readWord() (string, error) {
+	var sb strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			return sb.String(), nil
+
+		case (c == '<' || c == '>') && p.pos+1 < len(p.src) && p.src[p.pos+1] == '(':
+			// 进程替换
+			p.pos += 2
+			inner, err := p.readBalanced(true)
+			if err != nil {
+				return "", err
+			}
+			if err := p.addNested(inner); err != nil {
+				return "", err
+			}
+			sb.WriteString(string(c) + "(" + inner + ")")
+
+		case strings.ContainsRune(";|&()<>", rune(c)):
+			return sb.String(), nil
+
+		case c == '\\':
+			p.pos++
+			if p.pos < len(p.src) {
+				if p.src[p.pos] != '\n' {
+					sb.WriteByte(p.src[p.pos])
+				}
+				p.pos++
+			}
+
+		case c == '\'':
+			end := strings.IndexByte(p.src[p.pos+1:], '\'')
+			if end < 0 {
+				return "", errors.New("unterminated single quote")
+			}
+			sb.WriteString(p.src[p.pos+1 : p.pos+1+end])
+			p.pos += end + 2
+
+		case c == '"':
+			p.pos++
+			if err := p.readDoubleQuoted(&sb); err != nil {
+				return "", err
+			}
+
+		case c == '$' || c == '`':
+			if err := p.readDollar(&sb); err != nil {
+				return "", err
+			}
+
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+	return sb.String(), nil
+}
+
+func (p *shellParser) readDoubleQuoted(sb *strings.Builder) error {
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return nil
+		case c == '\\' && p.pos+1 < len(p.src) && strings.ContainsRune("$`\"\\\n", rune(p.src[p.pos+1])):
+			if p.src[p.pos+1] != '\n' {
+				sb.WriteByte(p.src[p.pos+1])
+			}
+			p.pos += 2
+		case c == '$' || c == '`':
+			if err := p.readDollar(sb); err != nil {
+				return err
+			}
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+	return errors.New("unterminated double quote")
+}
+
+// readDollar 读取 $(...)、`...`、$((...))、${...}、$'...' 或普通的 $
+func (p *shellParser) readDollar(sb *strings.Builder) error {
+	rest := p.src[p.pos:]
+	switch {
+	case strings.HasPrefix(rest, "$(("):
+		// 算术展开，不是命令
+		p.pos += 2
+		inner, err := p.readBalanced(false)
+		if err != nil {
+			return err
+		}
+		sb.WriteString("$(" + inner + ")")
+
+	case strings.HasPrefix(rest, "$("):
+		p.pos += 2
+		inner, err := p.readBalanced(true)
+		if err != nil {
+			return err
+		}
+		if err := p.addNested(inner); err != nil {
+			return err
+		}
+		sb.WriteString("$(" + inner + ")")
+
+	case strings.HasPrefix(rest, "`"):
+		end := strings.IndexByte(rest[1:], '`')
+		if end < 0 {
+			return errors.New("unterminated backquote")
+		}
+		inner := rest[1 : 1+end]
+		p.pos += end + 2
+		if err := p.addNested(inner); err != nil {
+			return err
+		}
+		sb.WriteString("`" + inner + "`")
+
+	case strings.HasPrefix(rest, "${"):
+		end := strings.IndexByte(rest, '}')
+		if end < 0 {
+			return errors.New("unterminated parameter expansion")
+		}
+		sb.WriteString(rest[:end+1])
+		p.pos += end + 1
+
+	case strings.HasPrefix(rest, "$'"):
+		p.pos += 2
+		for p.pos < len(p.src) {
+			c := p.src[p.pos]
+			if c == '\'' {
+				p.pos++
+				return nil
+			}
+			if c == '\\' && p.pos+1 < len(p.src) {
+				sb.WriteString(ansiEscape(p.src[p.pos+1]))
+				p.pos += 2
+				continue
+			}
+			sb.WriteByte(c)
+			p.pos++
+		}
+		return errors.New("unterminated $' quote")
+
+	default:
+		sb.WriteByte('$')
+		p.pos++
+	}
+	return nil
+}
+
+func ansiEscape(c byte) string {
+	switch c {
+	case 'n':
+		return "\n"
+	case 't':
+		return "\t"
+	case 'r':
+		return "\r"
+	default:
+		return string(c)
+	}
+}
+
+// readBalanced 读取到与已读取的左括号匹配的右括号，返回括号内的内容
+// commands为true时内容是命令，其中here document的正文原样保留，不参与引号和括号的匹配，
+// 如 $(cat <<'EOF' ... EOF) 的正文中可以有单引号和不成对的括号；算术展开中的<<是移位
+func (p *shellParser) readBalanced(commands bool) (string, error) {
+	start := p.pos
+	depth := 1
+	// 当前行中等待读取正文的here document结束标记
+	var heredocs []string
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch c {
+		case '<':
+			rest := p.src[p.pos:]
+			if !commands || !strings.HasPrefix(rest, "<<") || strings.HasPrefix(rest, "<<<") {
+				break
+			}
+			// << 或 <<-
+			p.pos += 2
+			if p.pos < len(p.src) && p.src[p.pos] == '-' {
+				p.pos++
+			}
+			p.skipBlanks()
+			delimiter, err := p.readWord()
+			if err != nil {
+				return "", err
+			}
+			if delimiter == "" {
+				return "", errors.New("missing target for redirection <<")
+			}
+			heredocs = append(heredocs, delimiter)
+			continue
+		case '\n':
+			if len(heredocs) == 0 {
+				break
+			}
+			p.pos++
+			pending := p.heredocs
+			p.heredocs = heredocs
+			err := p.skipHeredocs()
+			p.heredocs, heredocs = pending, nil
+			if err != nil {
+				return "", err
+			}
+			continue
+		case '\\':
+			p.pos += 2
+			continue
+		case '\'':
+			end := strings.IndexByte(p.src[p.pos+1:], '\'')
+			if end < 0 {
+				return "", errors.New("unterminated single quote")
+			}
+			p.pos += end + 2
+			continue
+		case '"':
+			p.pos++
+			for p.pos < len(p.src) && p.src[p.pos] != '"' {
+				if p.src[p.pos] == '\\' {
+					p.pos++
+				}
+				p.pos++
+			}
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				inner := p.src[start:p.pos]
+				p.pos++
+				return inner, nil
+			}
+		}
+		p.pos++
+	}
+	return "", errors.New("unterminated command substitution")
+}
+
+func (p *shellParser) addNested(src string) error {
+	commands, err := p.parseNested(src)
+	if err != nil {
+		return err
+	}
+	p.nested = append(p.nested, commands...)
+	return nil
+}
@@ -0,0 +1,199 @@
+package types
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseShell(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    []string
+	}{
+		{"simple", "cat .env", []string{"cat .env"}},
+		{"quotes", `grep -n "a b" 'c d' e\ f`, []string{"grep -n a b c d e f"}},
+		{"list and pipe", "ls && cat a | grep x; echo done || true", []string{"ls", "cat a", "grep x", "echo done", "true"}},
+		{"subshell", "(cd dir && cat .env)", []string{"cd dir", "cat .env"}},
+		{"command substitution", `echo "$(cat .env.local)" x`, []string{"echo $(cat .env.local) x", "cat .env.local"}},
+		{"backquote", "echo `cat secret`", []string{"echo `cat secret`", "cat secret"}},
+		{"process substitution", "diff <(sort a) b", []string{"diff <(sort a) b", "sort a"}},
+		{"bash -c", `bash -c "source .env && env"`, []string{"bash -c source .env && env", "source .env", "env"}},
+		{"wrapped bash -c", `sudo sh -c 'cat /etc/shadow'`, []string{"sudo sh -c cat /etc/shadow", "cat /etc/shadow"}},
+		{"keywords", "if true; then cat a; fi", []string{"true", "cat a"}},
+		{"comment", "ls # cat .env", []string{"ls"}},
+		{"heredoc", "cat <<EOF > out\ncat .env\nEOF\nls", []string{"cat", "ls"}},
+		{"line continuation", "rm \\\n  -rf dir", []string{"rm -rf dir"}},
+		{"ansi quote", `echo $'a\tb'`, []string{"echo a\tb"}},
+		{"arithmetic", "echo $((1 + 2))", []string{"echo $((1 + 2))"}},
+		{"arithmetic shift", "echo $((1<<2))", []string{"echo $((1<<2))"}},
+		{"heredoc in substitution", "git commit -m \"$(cat <<'EOF'\nfix: thing\nEOF\n)\"", []string{"git commit -m $(cat <<'EOF'\nfix: thing\nEOF\n)", "cat"}},
+		{"heredoc apostrophe", "git commit -m \"$(cat <<'EOF'\nfix: don't break\nEOF\n)\"", []string{"git commit -m $(cat <<'EOF'\nfix: don't break\nEOF\n)", "cat"}},
+		{"heredoc unmatched parenthesis", "git commit -m \"$(cat <<-EOF\nfix: a) one b) two\n\tEOF\n)\" && ls", []string{"git commit -m $(cat <<-EOF\nfix: a) one b) two\n\tEOF\n)", "cat", "ls"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commands, err := ParseShell(tt.command)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, c := range commands {
+				got = append(got, strings.Join(c.Args, " "))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseShell(%q) = %q, want %q", tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseShellOperators(t *testing.T) {
+	commands, err := ParseShell("a | b |& c && d || e; f & g\nh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ops []string
+	for _, c := range commands {
+		ops = append(ops, c.Op)
+	}
+	want := []string{"|", "|&", "&&", "||", ";", "&", "", ""}
+	if !reflect.DeepEqual(ops, want) {
+		t.Errorf("ops = %q, want %q", ops, want)
+	}
+	if !commands[0].Piped() || commands[2].Piped() {
+		t.Error("Piped reported wrong commands")
+	}
+}
+
+func TestParseShellRedirects(t *testing.T) {
+	commands, err := ParseShell("FOO=1 cmd <in >out 2>>err.log 2>&1 &>all <<<word")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commands) != 1 {
+		t.Fatalf("commands = %+v", commands)
+	}
+	c := commands[0]
+	if c.Name() != "cmd" || !reflect.DeepEqual(c.Assignments, []string{"FOO=1"}) {
+		t.Errorf("command = %+v", c)
+	}
+
+	want := []Redirect{
+		{Op: "<", Target: "in"},
+		{Op: ">", Target: "out"},
+		{Op: "2>>", Target: "err.log"},
+		{Op: "2>&", Target: "1"},
+		{Op: "&>", Target: "all"},
+		{Op: "<<<", Target: "word"},
+	}
+	if !reflect.DeepEqual(c.Redirects, want) {
+		t.Errorf("redirects = %+v, want %+v", c.Redirects, want)
+	}
+
+	var files []string
+	for _, r := range c.Redirects {
+		if r.IsFile() {
+			files = append(files, r.Target)
+		}
+	}
+	if !reflect.DeepEqual(files, []string{"in", "out", "err.log", "all"}) {
+		t.Errorf("file targets = %v", files)
+	}
+}
+
+func TestShellCommandUnwrap(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"sudo -u root rm -rf /tmp/x", "rm -rf /tmp/x"},
+		{"env -i A=1 B=2 git push", "git push"},
+		{"timeout -s KILL 10s nohup make", "make"},
+		{"xargs -I {} cat {}", "cat {}"},
+		{"/usr/bin/sudo /bin/rm x", "/bin/rm x"},
+		{"git status", "git status"},
+	}
+
+	for _, tt := range tests {
+		commands, err := ParseShell(tt.command)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := strings.Join(commands[0].Unwrap().Args, " ")
+		if got != tt.want {
+			t.Errorf("Unwrap(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestShellCommandDirTarget(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+		ok      bool
+	}{
+		{"cd /tmp", "/tmp", true},
+		{"cd", "~", true},
+		{"cd -P ../repo", "../repo", true},
+		{"cd -", "-", true},
+		{"pushd src", "src", true},
+		{"builtin cd sub", "sub", true},
+		{"ls /tmp", "", false},
+	}
+	for _, tt := range tests {
+		commands, err := ParseShell(tt.command)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := commands[0].Unwrap().DirTarget()
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("DirTarget(%q) = %q, %v; want %q, %v", tt.command, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseShellErrors(t *testing.T) {
+	for _, command := range []string{
+		`echo "unterminated`,
+		`echo 'unterminated`,
+		"echo $(ls",
+		"echo `ls",
+		"cat >",
+		"cat <<EOF\nbody",
+		"echo \"$(cat <<EOF\nbody\n)\"",
+	} {
+		if _, err := ParseShell(command); err == nil {
+			t.Errorf("ParseShell(%q) succeeded, want error", command)
+		}
+	}
+}
+
+func TestExpandVariables(t *testing.T) {
+	t.Setenv("BUILD_DIR", "/var/build")
+	tests := []struct {
+		word, cwd string
+		vars      map[string]string
+		want      string
+		ok        bool
+	}{
+		{"$BUILD_DIR/out", "", nil, "/var/build/out", true},
+		{"${BUILD_DIR}/out", "", nil, "/var/build/out", true},
+		{"$BUILD_DIR/x", "", map[string]string{"BUILD_DIR": "build"}, "build/x", true},
+		{"$PWD/x", "/project", nil, "/project/x", true},
+		{"$PWD/x", "", nil, "", false},
+		{"$UNSET_VARIABLE_FOR_TEST/x", "/project", nil, "", false},
+		{"$(pwd)/x", "/project", nil, "", false},
+		{"`pwd`/x", "/project", nil, "", false},
+		{"${BUILD_DIR:-/tmp}", "/project", nil, "", false},
+		{"plain", "", nil, "plain", true},
+	}
+	for _, tt := range tests {
+		got, ok := ExpandVariables(tt.word, tt.cwd, tt.vars)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("ExpandVariables(%q, %q) = %q, %v, want %q, %v", tt.word, tt.cwd, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// BashInput Bash工具的输入
+type BashInput struct {
+	Command string `json:"command"`
+	// 超时时间，单位毫秒
+	Timeout         int    `json:"timeout,omitempty"`
+	Description     string `json:"description,omitempty"`
+	RunInBackground bool   `json:"run_in_background,omitempty"`
+}
+
+// EditInput Edit工具的输入
+type EditInput struct {
+	FilePath   string `json:"file_path"`
+	OldString  string `json:"old_string"`
+	NewString  string `json:"new_string"`
+	ReplaceAll bool   `json:"replace_all,omitempty"`
+}
+
+// EditOperation MultiEdit中的单次编辑
+type EditOperation struct {
+	OldString  string `json:"old_string"`
+	NewString  string `json:"new_string"`
+	ReplaceAll bool   `json:"replace_all,omitempty"`
+}
+
+// MultiEditInput MultiEdit工具的输入
+type MultiEditInput struct {
+	FilePath string          `json:"file_path"`
+	Edits    []EditOperation `json:"edits"`
+}
+
+// WriteInput Write工具的输入
+type WriteInput struct {
+	FilePath string `json:"file_path"`
+	Content  string `json:"content"`
+}
+
+// ReadInput Read工具的输入
+type ReadInput struct {
+	FilePath string `json:"file_path"`
+	// 起始行号
+	Offset int `json:"offset,omitempty"`
+	// 读取的行数
+	Limit int `json:"limit,omitempty"`
+}
+
+// GlobInput Glob工具的输入
+type GlobInput struct {
+	Pattern string `json:"pattern"`
+	Path    string `json:"path,omitempty"`
+}
+
+// GrepInput Grep工具的输入
+type GrepInput struct {
+	Pattern string `json:"pattern"`
+	Path    string `json:"path,omitempty"`
+	Glob    string `json:"glob,omitempty"`
+	Type    string `json:"type,omitempty"`
+	// content、files_with_matches 或 count
+	OutputMode      string `json:"output_mode,omitempty"`
+	CaseInsensitive bool   `json:"-i,omitempty"`
+	LineNumbers     bool   `json:"-n,omitempty"`
+	Before          int    `json:"-B,omitempty"`
+	After           int    `json:"-A,omitempty"`
+	Context         int    `json:"-C,omitempty"`
+	Multiline       bool   `json:"multiline,omitempty"`
+	HeadLimit       int    `json:"head_limit,omitempty"`
+}
+
+// WebFetchInput WebFetch工具的输入
+type WebFetchInput struct {
+	URL    string `json:"url"`
+	Prompt string `json:"prompt"`
+}
+
+// WebSearchInput WebSearch工具的输入
+type WebSearchInput struct {
+	Query          string   `json:"query"`
+	AllowedDomains []string `json:"allowed_domains,omitempty"`
+	BlockedDomains []string `json:"blocked_domains,omitempty"`
+}
+
+// TaskInput Task工具（子代理）的输入
+type TaskInput struct {
+	Description  string `json:"description"`
+	Prompt       string `json:"prompt"`
+	SubagentType string `json:"subagent_type,omitempty"`
+}
+
+// Todo TodoWrite中的待办事项
+type Todo struct {
+	Content string `json:"content"`
+	// pending、in_progress 或 completed
+	Status     string `json:"status"`
+	ActiveForm string `json:"activeForm,omitempty"`
+	Priority   string `json:"priority,omitempty"`
+	ID         string `json:"id,omitempty"`
+}
+
+// TodoWriteInput TodoWrite工具的输入
+type TodoWriteInput struct {
+	Todos []Todo `json:"todos"`
+}
+
+// NotebookEditInput NotebookEdit工具的输入
+type NotebookEditInput struct {
+	NotebookPath string `json:"notebook_path"`
+	CellID       string `json:"cell_id,omitempty"`
+	NewSource    string `json:"new_source"`
+	// code 或 markdown
+	CellType string `json:"cell_type,omitempty"`
+	// replace、insert 或 delete
+	EditMode string `json:"edit_mode,omitempty"`
+}
+
+// Decode 将原始工具输入解码到v中，可用于MCP等没有内置类型的工具
+func (t *ToolInput) Decode(v any) error {
+	data, err := json.Marshal(t.ToolInput)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s input: %w", t.ToolName, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid %s input: %w", t.ToolName, err)
+	}
+	return nil
+}
+
+// decodeTool 检查工具名后解码为对应的类型
+func decodeTool[T any](t *ToolInput, toolName string) (*T, error) {
+	if t.ToolName != toolName {
+		return nil, fmt.Errorf("tool is %s, not %s", t.ToolName, toolName)
+	}
+	var v T
+	if err := t.Decode(&v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (t *ToolInput) AsBash() (*BashInput, error) {
+	return decodeTool[BashInput](t, "Bash")
+}
+
+func (t *ToolInput) AsEdit() (*EditInput, error) {
+	return decodeTool[EditInput](t, "Edit")
+}
+
+func (t *ToolInput) AsMultiEdit() (*MultiEditInput, error) {
+	return decodeTool[MultiEditInput](t, "MultiEdit")
+}
+
+func (t *ToolInput) AsWrite() (*WriteInput, error) {
+	return decodeTool[WriteInput](t, "Write")
+}
+
+func (t *ToolInput) AsRead() (*ReadInput, error) {
+	return decodeTool[ReadInput](t, "Read")
+}
+
+func (t *ToolInput) AsGlob() (*GlobInput, error) {
+	return decodeTool[GlobInput](t, "Glob")
+}
+
+func (t *ToolInput) AsGrep() (*GrepInput, error) {
+	return decodeTool[GrepInput](t, "Grep")
+}
+
+func (t *ToolInput) AsWebFetch() (*WebFetchInput, error) {
+	return decodeTool[WebFetchInput](t, "WebFetch")
+}
+
+func (t *ToolInput) AsWebSearch() (*WebSearchInput, error) {
+	return decodeTool[WebSearchInput](t, "WebSearch")
+}
+
+func (t *ToolInput) AsTask() (*TaskInput, error) {
+	return decodeTool[TaskInput](t, "Task")
+}
+
+func (t *ToolInput) AsTodoWrite() (*TodoWriteInput, error) {
+	return decodeTool[TodoWriteInput](t, "TodoWrite")
+}
+
+func (t *ToolInput) AsNotebookEdit() (*NotebookEditInput, error) {
+	return decodeTool[NotebookEditInput](t, "NotebookEdit")
+}
+
+// InputRewrite 工具输入的副本，修改后传给PreToolUseOutput.UpdateInput
+type InputRewrite map[string]any
+
+// Rewrite 复制原始工具输入，未修改的字段（包括没有类型定义的字段）会原样保留
+func (t *ToolInput) Rewrite() InputRewrite {
+	r := make(InputRewrite, len(t.ToolInput))
+	for k, v := range t.ToolInput {
+		r[k] = v
+	}
+	return r
+}
+
+// Set 设置字段
+func (r InputRewrite) Set(key string, value any) InputRewrite {
+	r[key] = value
+	return r
+}
+
+// Delete 删除字段
+func (r InputRewrite) Delete(key string) InputRewrite {
+	delete(r, key)
+	return r
+}
+
+// toInputMap 将类型化的工具输入转换为map
+func toInputMap(input any) (map[string]any, error) {
+	switch v := input.(type) {
+	case InputRewrite:
+		return v, nil
+	case map[string]any:
+		return v, nil
+	}
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("invalid updated input: %w", err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid updated input: %w", err)
+	}
+	return m, nil
+}
@@ -0,0 +1,90 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func parseToolInput(t *testing.T, data string) *ToolInput {
+	t.Helper()
+	var input ToolInput
+	if err := json.Unmarshal([]byte(data), &input); err != nil {
+		t.Fatal(err)
+	}
+	return &input
+}
+
+func TestAsBash(t *testing.T) {
+	input := parseToolInput(t, `{"tool_name":"Bash","tool_input":{"command":"go test ./...","timeout":60000,"run_in_background":true}}`)
+
+	bash, err := input.AsBash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &BashInput{Command: "go test ./...", Timeout: 60000, RunInBackground: true}
+	if !reflect.DeepEqual(bash, want) {
+		t.Errorf("AsBash = %+v, want %+v", bash, want)
+	}
+
+	if _, err := input.AsWrite(); err == nil {
+		t.Error("AsWrite on a Bash input should fail")
+	}
+}
+
+func TestAsMultiEdit(t *testing.T) {
+	input := parseToolInput(t, `{"tool_name":"MultiEdit","tool_input":{"file_path":"/a.go","edits":[{"old_string":"a","new_string":"b"},{"old_string":"c","new_string":"d","replace_all":true}]}}`)
+
+	multi, err := input.AsMultiEdit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &MultiEditInput{
+		FilePath: "/a.go",
+		Edits: []EditOperation{
+			{OldString: "a", NewString: "b"},
+			{OldString: "c", NewString: "d", ReplaceAll: true},
+		},
+	}
+	if !reflect.DeepEqual(multi, want) {
+		t.Errorf("AsMultiEdit = %+v, want %+v", multi, want)
+	}
+}
+
+func TestAsGrep(t *testing.T) {
+	input := parseToolInput(t, `{"tool_name":"Grep","tool_input":{"pattern":"SECRET","path":".env","-i":true,"-C":2,"output_mode":"content"}}`)
+
+	grep, err := input.AsGrep()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &GrepInput{Pattern: "SECRET", Path: ".env", CaseInsensitive: true, Context: 2, OutputMode: "content"}
+	if !reflect.DeepEqual(grep, want) {
+		t.Errorf("AsGrep = %+v, want %+v", grep, want)
+	}
+}
+
+func TestDecodeUnknownTool(t *testing.T) {
+	input := parseToolInput(t, `{"tool_name":"mcp__github__create_issue","tool_input":{"title":"bug","labels":["p1"]}}`)
+
+	var issue struct {
+		Title  string   `json:"title"`
+		Labels []string `json:"labels"`
+	}
+	if err := input.Decode(&issue); err != nil {
+		t.Fatal(err)
+	}
+	if issue.Title != "bug" || !reflect.DeepEqual(issue.Labels, []string{"p1"}) {
+		t.Errorf("Decode = %+v", issue)
+	}
+	if input.ToolInput["title"] != "bug" {
+		t.Error("raw tool input should remain accessible")
+	}
+}
+
+func TestDecodeInvalidInput(t *testing.T) {
+	input := parseToolInput(t, `{"tool_name":"Read","tool_input":{"file_path":"/a.go","offset":"ten"}}`)
+	if _, err := input.AsRead(); err == nil {
+		t.Error("expected error for invalid offset")
+	}
+}
@@ -0,0 +1,315 @@
+package types
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// TranscriptEntry transcript文件（JSONL）中的一行
+type TranscriptEntry struct {
+	// user、assistant、summary 或 system
+	Type        string             `json:"type"`
+	UUID        string             `json:"uuid"`
+	ParentUUID  string             `json:"parentUuid"`
+	SessionID   string             `json:"sessionId"`
+	Timestamp   time.Time          `json:"timestamp"`
+	Cwd         string             `json:"cwd"`
+	IsSidechain bool               `json:"isSidechain"`
+	Message     *TranscriptMessage `json:"message,omitempty"`
+	// summary类型的摘要内容
+	Summary string `json:"summary,omitempty"`
+}
+
+// TranscriptMessage 用户或助手的消息
+type TranscriptMessage struct {
+	ID         string         `json:"id"`
+	Role       string         `json:"role"`
+	Model      string         `json:"model"`
+	Content    MessageContent `json:"content"`
+	StopReason string         `json:"stop_reason"`
+	Usage      *Usage         `json:"usage,omitempty"`
+}
+
+// Usage 一条助手消息的token用量
+type Usage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+// Add 累加token用量
+func (u *Usage) Add(other Usage) {
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.CacheCreationInputTokens += other.CacheCreationInputTokens
+	u.CacheReadInputTokens += other.CacheReadInputTokens
+}
+
+// ContentBlock 消息中的内容块
+type ContentBlock struct {
+	// text、thinking、tool_use、tool_result 或 image
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+
+	// tool_use
+	ID    string         `json:"id,omitempty"`
+	Name  string         `json:"name,omitempty"`
+	Input map[string]any `json:"input,omitempty"`
+
+	// tool_result
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   json.RawMessage `json:"content,omitempty"`
+	IsError   bool            `json:"is_error,omitempty"`
+}
+
+// ResultText 返回tool_result的文本内容
+func (b ContentBlock) ResultText() string {
+	if len(b.Content) == 0 {
+		return ""
+	}
+
+	var text string
+	if err := json.Unmarshal(b.Content, &text); err == nil {
+		return text
+	}
+
+	var blocks []ContentBlock
+	if err := json.Unmarshal(b.Content, &blocks); err != nil {
+		return ""
+	}
+	return MessageContent(blocks).Text()
+}
+
+// MessageContent 消息内容，字符串形式的内容会被转换为单个text块
+type MessageContent []ContentBlock
+
+func (c *MessageContent) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*c = MessageContent{{Type: "text", Text: text}}
+		return nil
+	}
+
+	var blocks []ContentBlock
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		return err
+	}
+	*c = blocks
+	return nil
+}
+
+// Text 拼接所有text块的内容
+func (c MessageContent) Text() string {
+	var parts []string
+	for _, block := range c {
+		if block.Type == "text" && block.Text != "" {
+			parts = append(parts, block.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// TranscriptReader 逐行读取transcript文件
+type TranscriptReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+// NewTranscriptReader 创建transcript读取器
+func NewTranscriptReader(r io.Reader) *TranscriptReader {
+	return &TranscriptReader{r: bufio.NewReader(r)}
+}
+
+// Next 返回下一条记录，读取完毕时返回io.EOF
+// Claude Code可能正在写入最后一行，因此末尾不完整的行会被忽略
+func (t *TranscriptReader) Next() (*TranscriptEntry, error) {
+	for {
+		data, err := t.r.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		complete := err == nil
+		if !complete && len(data) == 0 {
+			return nil, io.EOF
+		}
+		t.line++
+
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			if !complete {
+				return nil, io.EOF
+			}
+			continue
+		}
+
+		var entry TranscriptEntry
+		if jsonErr := json.Unmarshal(data, &entry); jsonErr != nil {
+			if !complete {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("invalid transcript entry at line %d: %w", t.line, jsonErr)
+		}
+		return &entry, nil
+	}
+}
+
+// ReadTranscript 依次对transcript中的每条记录调用fn，fn返回错误时停止
+func ReadTranscript(path string, fn func(*TranscriptEntry) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open transcript: %w", err)
+	}
+	defer f.Close()
+
+	reader := NewTranscriptReader(f)
+	for {
+		entry, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+}
+
+// ToolCall 一次工具调用及其结果
+type ToolCall struct {
+	ID        string
+	Name      string
+	Input     map[string]any
+	Timestamp time.Time
+	// 工具结果，工具尚未返回时为nil
+	Result *ContentBlock
+}
+
+// Succeeded 判断工具调用是否已返回且没有出错
+func (c ToolCall) Succeeded() bool {
+	return c.Result != nil && !c.Result.IsError
+}
+
+// ToolCalls 返回transcript中的所有工具调用，按调用顺序排列
+func ToolCalls(path string) ([]ToolCall, error) {
+	var calls []ToolCall
+	index := make(map[string]int)
+
+	err := ReadTranscript(path, func(entry *TranscriptEntry) error {
+		if entry.Message == nil {
+			return nil
+		}
+		for _, block := range entry.Message.Content {
+			switch block.Type {
+			case "tool_use":
+				index[block.ID] = len(calls)
+				calls = append(calls, ToolCall{
+					ID:        block.ID,
+					Name:      block.Name,
+					Input:     block.Input,
+					Timestamp: entry.Timestamp,
+				})
+			case "tool_result":
+				if i, ok := index[block.ToolUseID]; ok {
+					result := block
+					calls[i].Result = &result
+				}
+			}
+		}
+		return nil
+	})
+	return calls, err
+}
+
+// LastToolCalls 返回最后n次工具调用
+func LastToolCalls(path string, n int) ([]ToolCall, error) {
+	calls, err := ToolCalls(path)
+	if err != nil {
+		return nil, err
+	}
+	if n >= 0 && len(calls) > n {
+		calls = calls[len(calls)-n:]
+	}
+	return calls, nil
+}
+
+// EditedFiles 返回本次会话中成功修改过的文件，按首次修改的顺序排列
+func EditedFiles(path string) ([]string, error) {
+	calls, err := ToolCalls(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	seen := make(map[string]bool)
+	for _, call := range calls {
+		if !call.Succeeded() {
+			continue
+		}
+
+		var file string
+		switch call.Name {
+		case "Write", "Edit", "MultiEdit":
+			file, _ = call.Input["file_path"].(string)
+		case "NotebookEdit":
+			file, _ = call.Input["notebook_path"].(string)
+		}
+		if file != "" && !seen[file] {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// LastAssistantMessage 返回最后一条包含文本的助手消息
+func LastAssistantMessage(path string) (string, error) {
+	var last string
+	err := ReadTranscript(path, func(entry *TranscriptEntry) error {
+		if entry.Type != "assistant" || entry.Message == nil {
+			return nil
+		}
+		if text := entry.Message.Content.Text(); text != "" {
+			last = text
+		}
+		return nil
+	})
+	return last, err
+}
+
+// TotalUsage 返回本次会话的token用量
+// 同一条助手消息会分多行记录且每行携带相同的用量，按消息ID只统计一次
+func TotalUsage(path string) (Usage, error) {
+	usages := make(map[string]Usage)
+	var order []string
+
+	err := ReadTranscript(path, func(entry *TranscriptEntry) error {
+		if entry.Type != "assistant" || entry.Message == nil || entry.Message.Usage == nil {
+			return nil
+		}
+		id := entry.Message.ID
+		if id == "" {
+			id = entry.UUID
+		}
+		if _, ok := usages[id]; !ok {
+			order = append(order, id)
+		}
+		usages[id] = *entry.Message.Usage
+		return nil
+	})
+
+	var total Usage
+	for _, id := range order {
+		total.Add(usages[id])
+	}
+	return total, err
+}
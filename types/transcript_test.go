@@ -0,0 +1,107 @@
+package types
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sampleTranscript = `{"type":"summary","summary":"Refactor config","leafUuid":"u0"}
+{"type":"user","uuid":"u1","sessionId":"s1","timestamp":"2025-08-01T10:00:00.000Z","message":{"role":"user","content":"Fix the config loader"}}
+{"type":"assistant","uuid":"u2","sessionId":"s1","timestamp":"2025-08-01T10:00:01.000Z","message":{"id":"msg_1","role":"assistant","model":"m","content":[{"type":"text","text":"Reading the file."}],"usage":{"input_tokens":10,"output_tokens":5,"cache_read_input_tokens":100}}}
+{"type":"assistant","uuid":"u3","sessionId":"s1","timestamp":"2025-08-01T10:00:02.000Z","message":{"id":"msg_1","role":"assistant","model":"m","content":[{"type":"tool_use","id":"t1","name":"Read","input":{"file_path":"/p/config.go"}}],"usage":{"input_tokens":10,"output_tokens":5,"cache_read_input_tokens":100}}}
+{"type":"user","uuid":"u4","sessionId":"s1","timestamp":"2025-08-01T10:00:03.000Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"package config"}]}}
+
+{"type":"assistant","uuid":"u5","sessionId":"s1","timestamp":"2025-08-01T10:00:04.000Z","message":{"id":"msg_2","role":"assistant","model":"m","content":[{"type":"tool_use","id":"t2","name":"Edit","input":{"file_path":"/p/config.go","old_string":"a","new_string":"b"}},{"type":"tool_use","id":"t3","name":"Write","input":{"file_path":"/p/missing.go","content":""}}],"usage":{"input_tokens":20,"output_tokens":7}}}
+{"type":"user","uuid":"u6","sessionId":"s1","timestamp":"2025-08-01T10:00:05.000Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t2","content":[{"type":"text","text":"updated"}]},{"type":"tool_result","tool_use_id":"t3","content":"permission denied","is_error":true}]}}
+{"type":"assistant","uuid":"u7","sessionId":"s1","timestamp":"2025-08-01T10:00:06.000Z","message":{"id":"msg_3","role":"assistant","model":"m","content":[{"type":"thinking","thinking":"done"},{"type":"text","text":"Config loader fixed."}],"usage":{"input_tokens":30,"output_tokens":9}}}
+{"type":"assistant","uuid":"u8","sessionId":"s1","timestamp":"2025-08-01T10:00:07.000Z","message":{"id":"msg_4","role":"assis`
+
+func writeTranscript(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTranscriptReader(t *testing.T) {
+	reader := NewTranscriptReader(strings.NewReader(sampleTranscript))
+
+	var got []string
+	for {
+		entry, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, entry.Type)
+	}
+
+	// 末尾不完整的行被忽略
+	want := []string{"summary", "user", "assistant", "assistant", "user", "assistant", "user", "assistant"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("types = %v, want %v", got, want)
+	}
+}
+
+func TestTranscriptReaderInvalidLine(t *testing.T) {
+	reader := NewTranscriptReader(strings.NewReader("{\"type\":\"user\"}\nnot json\n"))
+	if _, err := reader.Next(); err != nil {
+		t.Fatal(err)
+	}
+	_, err := reader.Next()
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("err = %v, want error at line 2", err)
+	}
+}
+
+func TestTranscriptHelpers(t *testing.T) {
+	path := writeTranscript(t, sampleTranscript)
+
+	calls, err := LastToolCalls(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 || calls[0].ID != "t2" || calls[1].ID != "t3" {
+		t.Fatalf("LastToolCalls = %+v", calls)
+	}
+	if !calls[0].Succeeded() || calls[0].Result.ResultText() != "updated" {
+		t.Errorf("t2 result = %+v", calls[0].Result)
+	}
+	if calls[1].Succeeded() || calls[1].Result.ResultText() != "permission denied" {
+		t.Errorf("t3 result = %+v", calls[1].Result)
+	}
+
+	files, err := EditedFiles(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(files, []string{"/p/config.go"}) {
+		t.Errorf("EditedFiles = %v", files)
+	}
+
+	last, err := LastAssistantMessage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last != "Config loader fixed." {
+		t.Errorf("LastAssistantMessage = %q", last)
+	}
+
+	usage, err := TotalUsage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Usage{InputTokens: 60, OutputTokens: 21, CacheReadInputTokens: 100}
+	if usage != want {
+		t.Errorf("TotalUsage = %+v, want %+v", usage, want)
+	}
+}
@@ -1,8 +1,10 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 )
 
 const (
@@ -23,6 +25,39 @@ type BaseHookInput struct {
 	SessionID      string `json:"session_id"`
 	TranscriptPath string `json:"transcript_path"`
 	HookEventName  string `json:"hook_event_name"`
+	// hook被调用时的工作目录
+	Cwd string `json:"cwd"`
+	// 当前的权限模式：default、plan、acceptEdits 或 bypassPermissions
+	PermissionMode string `json:"permission_mode,omitempty"`
+	// 本次调用匹配到的插件匹配器标识，由宿主填充
+	Matched []string `json:"-"`
+	// 宿主不认识的输入字段，用于读取新版本Claude Code增加的字段
+	Extra map[string]json.RawMessage `json:"-"`
+	// 原始输入
+	Raw json.RawMessage `json:"-"`
+}
+
+// ExtraField 将未知字段key解码到v中，字段不存在时返回false
+func (b *BaseHookInput) ExtraField(key string, v any) (bool, error) {
+	raw, ok := b.Extra[key]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return true, fmt.Errorf("invalid field %q: %w", key, err)
+	}
+	return true, nil
+}
+
+// MatchedBy 判断本次调用是否由指定标识的匹配器触发
+// 标识为Matcher.ID，未设置ID时为Matcher.Pattern
+func (b *BaseHookInput) MatchedBy(id string) bool {
+	for _, matched := range b.Matched {
+		if matched == id {
+			return true
+		}
+	}
+	return false
 }
 
 type ToolInput struct {
@@ -39,6 +74,24 @@ func (t *ToolInput) GetFilePath() string {
 	return filePath
 }
 
+// GetTargetPath 返回工具操作的路径，依次取file_path、notebook_path、path
+func (t *ToolInput) GetTargetPath() string {
+	for _, key := range []string{"file_path", "notebook_path", "path"} {
+		if path, ok := t.ToolInput[key].(string); ok && path != "" {
+			return path
+		}
+	}
+	return ""
+}
+
+// ProjectDir 返回项目根目录，优先使用CLAUDE_PROJECT_DIR环境变量，否则为hook的工作目录
+func (b *BaseHookInput) ProjectDir() string {
+	if dir := os.Getenv("CLAUDE_PROJECT_DIR"); dir != "" {
+		return dir
+	}
+	return b.Cwd
+}
+
 type PostToolUseInput struct {
 	ToolInput
 	ToolResponse map[string]any `json:"tool_response"`
@@ -59,6 +112,19 @@ type SubagentStopInput struct {
 	StopHookActive bool `json:"stop_hook_active"`
 }
 
+type PreCompactInput struct {
+	BaseHookInput
+	// manual 或 auto
+	Trigger            string `json:"trigger"`
+	CustomInstructions string `json:"custom_instructions"`
+}
+
+type SessionStartInput struct {
+	BaseHookInput
+	// startup、resume、clear 或 compact
+	Source string `json:"source"`
+}
+
 type BaseHookOutput struct {
 	// Hook执行后，claude是否继续（默认为true)
 	// 当continue为false，claude在hooks运行后停止处理
@@ -95,26 +161,52 @@ func ptr[T any](input T) *T {
 
 // PreToolUseOutput
 //
-//	approve: reason显示给用户✅
+//	approve: reason显示给用户
 //	block: reason显示给claude
-//	其他： 现有的决策流程
-type PreToolUseOutput DecisionOutput
+//	其他： 现有的决策流程✅
+//
+// UpdateInput可以修改工具输入，多个插件的修改按执行顺序叠加
+type PreToolUseOutput struct {
+	DecisionOutput
+	HookSpecificOutput *PreToolUseSpecificOutput `json:"hookSpecificOutput,omitempty"`
+}
+
+// PreToolUseSpecificOutput PreToolUse的hookSpecificOutput
+type PreToolUseSpecificOutput struct {
+	HookEventName string `json:"hookEventName"`
+	// allow、deny 或 ask，设置后优先于顶层的decision
+	PermissionDecision       string `json:"permissionDecision,omitempty"`
+	PermissionDecisionReason string `json:"permissionDecisionReason,omitempty"`
+	// 修改后的完整工具输入，Claude Code会用它代替原始输入执行工具
+	UpdatedInput map[string]any `json:"updatedInput,omitempty"`
+}
 
 // PostToolUseOutput
 // 默认值: 不执行任何操作，reason被忽略✅
 // Block(): 用reason提示claude
-type PostToolUseOutput DecisionOutput
+// AddContext(): 不阻止，向claude提供额外的上下文
+type PostToolUseOutput struct {
+	DecisionOutput
+	HookSpecificOutput *PostToolUseSpecificOutput `json:"hookSpecificOutput,omitempty"`
+}
+
+// PostToolUseSpecificOutput PostToolUse的hookSpecificOutput
+type PostToolUseSpecificOutput struct {
+	HookEventName string `json:"hookEventName"`
+	// 提供给claude的额外上下文
+	AdditionalContext string `json:"additionalContext,omitempty"`
+}
 
 // StopOutput
 // 默认值: 允许claude停止，reason被忽略✅
 // NotAllowed()：不允许claude停止，并提供信息给claude以供继续
 type StopOutput DecisionOutput
 
+// Default 插件返回nil时的结果：没有决策，进入Claude Code现有的权限确认流程
 func (o *PreToolUseOutput) Default() {
 	if o.Continue == nil {
 		o.Continue = ptr(true)
 	}
-	o.Approve(true, "")
 }
 
 // Approve
@@ -139,6 +231,41 @@ func (o *PreToolUseOutput) Approve(approved bool, msg ...string) *PreToolUseOutp
 	return o
 }
 
+// UpdateInput 用input替换工具输入，input可以是BashInput等类型化的输入、InputRewrite或map
+// Claude Code会用完整的updatedInput代替原始输入，因此input必须包含工具需要的所有字段
+func (o *PreToolUseOutput) UpdateInput(input any) *PreToolUseOutput {
+	updated, err := toInputMap(input)
+	if err != nil {
+		o.Approve(false, err.Error())
+		return o
+	}
+	o.specificOutput().UpdatedInput = updated
+	return o
+}
+
+// Ask 要求用户确认本次工具调用，reason显示给用户
+func (o *PreToolUseOutput) Ask(reason string) *PreToolUseOutput {
+	specific := o.specificOutput()
+	specific.PermissionDecision = "ask"
+	specific.PermissionDecisionReason = reason
+	return o
+}
+
+func (o *PreToolUseOutput) specificOutput() *PreToolUseSpecificOutput {
+	if o.HookSpecificOutput == nil {
+		o.HookSpecificOutput = &PreToolUseSpecificOutput{HookEventName: "PreToolUse"}
+	}
+	return o.HookSpecificOutput
+}
+
+// UpdatedInput 返回修改后的工具输入，没有修改时返回nil
+func (o *PreToolUseOutput) UpdatedInput() map[string]any {
+	if o.HookSpecificOutput == nil {
+		return nil
+	}
+	return o.HookSpecificOutput.UpdatedInput
+}
+
 func (o *PostToolUseOutput) Default() {
 	if o.Continue == nil {
 		o.Continue = ptr(true)
@@ -150,6 +277,18 @@ func (o *PostToolUseOutput) Block(msg string) *PostToolUseOutput {
 	return o
 }
 
+// AddContext 添加提供给claude的额外上下文，多次调用按顺序拼接
+func (o *PostToolUseOutput) AddContext(context string) *PostToolUseOutput {
+	if o.HookSpecificOutput == nil {
+		o.HookSpecificOutput = &PostToolUseSpecificOutput{HookEventName: "PostToolUse"}
+	}
+	if o.HookSpecificOutput.AdditionalContext != "" {
+		o.HookSpecificOutput.AdditionalContext += "\n"
+	}
+	o.HookSpecificOutput.AdditionalContext += context
+	return o
+}
+
 func (o *StopOutput) Default() {
 	if o.Continue == nil {
 		o.Continue = ptr(true)
@@ -189,15 +328,16 @@ func (r Result) IsSuccess() bool {
 	return r.Code == ExitCodeSuccess
 }
 
+// ExitWithMessage 以JSON输出模式写出结果并退出：有JSON数据时写到stdout并以0退出，否则错误写到stderr
+//
+// Deprecated: 使用HookResult.Exit指定输出模式
 func (r Result) ExitWithMessage() {
-	if r.IsSuccess() {
-		if len(r.Data) > 0 {
-			_, _ = fmt.Fprintf(os.Stderr, r.Data)
-		}
-	} else {
-		if len(r.Error) > 0 {
-			_, _ = fmt.Fprintf(os.Stderr, r.Error)
-		}
+	if r.Code != ExitCodeError && len(r.Data) > 0 {
+		fmt.Fprintln(os.Stdout, r.Data)
+		os.Exit(ExitCodeSuccess)
+	}
+	if len(r.Error) > 0 {
+		fmt.Fprintln(os.Stderr, strings.TrimRight(r.Error, "\n"))
 	}
 	os.Exit(r.Code)
 }